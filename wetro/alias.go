@@ -0,0 +1,300 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrAliasNotFound is returned by an AliasStore for an unknown alias.
+var ErrAliasNotFound = errors.New("alias not found")
+
+// AliasStore maps alias names to collection IDs, so that callers can target
+// a stable name while the collection behind it is replaced.
+type AliasStore interface {
+
+	// Resolve returns the collection ID of an alias, or ErrAliasNotFound.
+	Resolve(ctx context.Context, alias string) (string, error)
+
+	// Set points an alias at a collection and returns the collection it
+	// pointed at before, if any.
+	Set(ctx context.Context, alias, collectionID string) (previous string, err error)
+
+	// Delete removes an alias. Deleting an unknown alias is not an error.
+	Delete(ctx context.Context, alias string) error
+}
+
+// AliasLister is implemented by alias stores that can list their aliases.
+// PruneCollections uses it to keep the collections aliases point at.
+type AliasLister interface {
+
+	// List returns the collection ID of every alias, by alias.
+	List(ctx context.Context) (map[string]string, error)
+}
+
+// MemoryAliasStore is an AliasStore that keeps aliases in memory.
+// It is safe for concurrent use.
+type MemoryAliasStore struct {
+	mu      sync.Mutex
+	aliases map[string]string
+}
+
+// NewMemoryAliasStore returns an empty in-memory alias store.
+func NewMemoryAliasStore() *MemoryAliasStore {
+	return &MemoryAliasStore{aliases: make(map[string]string)}
+}
+
+// Resolve implements AliasStore.
+func (s *MemoryAliasStore) Resolve(ctx context.Context, alias string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	collectionID, ok := s.aliases[alias]
+	if !ok {
+		return "", ErrAliasNotFound
+	}
+	return collectionID, nil
+}
+
+// Set implements AliasStore.
+func (s *MemoryAliasStore) Set(ctx context.Context, alias, collectionID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.aliases[alias]
+	s.aliases[alias] = collectionID
+	return previous, nil
+}
+
+// Delete implements AliasStore.
+func (s *MemoryAliasStore) Delete(ctx context.Context, alias string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.aliases, alias)
+	return nil
+}
+
+// List implements AliasLister.
+func (s *MemoryAliasStore) List(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.aliases), nil
+}
+
+// FileAliasStore is an AliasStore that keeps every alias in a single JSON
+// file. Changes are written to a temporary file that is renamed into place,
+// so readers in other processes see either the old or the new mapping, and
+// are made under a lock on a ".lock" file next to it, so processes sharing
+// the file do not lose each other's changes.
+type FileAliasStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileAliasStore returns an alias store backed by the file at path. The
+// file is created on the first change.
+func NewFileAliasStore(path string) (*FileAliasStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return &FileAliasStore{path: path}, nil
+}
+
+func (s *FileAliasStore) load() (map[string]string, error) {
+	aliases := make(map[string]string)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return aliases, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("invalid alias file %s: %w", s.path, err)
+	}
+	return aliases, nil
+}
+
+// withLock runs fn under the lock of the alias file, shared by every
+// process using it.
+func (s *FileAliasStore) withLock(fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return withFileLock(s.path+".lock", fn)
+}
+
+func (s *FileAliasStore) save(aliases map[string]string) error {
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data, 0o644)
+}
+
+// Resolve implements AliasStore.
+func (s *FileAliasStore) Resolve(ctx context.Context, alias string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	aliases, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	collectionID, ok := aliases[alias]
+	if !ok {
+		return "", ErrAliasNotFound
+	}
+	return collectionID, nil
+}
+
+// Set implements AliasStore.
+func (s *FileAliasStore) Set(ctx context.Context, alias, collectionID string) (string, error) {
+	var previous string
+	err := s.withLock(func() error {
+		aliases, err := s.load()
+		if err != nil {
+			return err
+		}
+		previous = aliases[alias]
+		aliases[alias] = collectionID
+		return s.save(aliases)
+	})
+	return previous, err
+}
+
+// Delete implements AliasStore.
+func (s *FileAliasStore) Delete(ctx context.Context, alias string) error {
+	return s.withLock(func() error {
+		aliases, err := s.load()
+		if err != nil {
+			return err
+		}
+		if _, ok := aliases[alias]; !ok {
+			return nil
+		}
+		delete(aliases, alias)
+		return s.save(aliases)
+	})
+}
+
+// List implements AliasLister.
+func (s *FileAliasStore) List(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// WithAliasStore makes QueryCollection and ChatWithCollection accept alias
+// names from store in place of collection IDs.
+func WithAliasStore(store AliasStore) ClientOption {
+	return func(c *apiClient) {
+		c.aliases = store
+	}
+}
+
+// resolveCollection returns the collection an alias points at, given full
+// IDs. IDs that are not aliases are returned unchanged.
+func (c *ragClient) resolveCollection(ctx context.Context, id string) (string, error) {
+	if c.client.aliases == nil || id == "" {
+		return id, nil
+	}
+	collectionID, err := c.client.aliases.Resolve(ctx, id)
+	if errors.Is(err, ErrAliasNotFound) {
+		return id, nil
+	} else if err != nil {
+		return "", fmt.Errorf("resolve alias %s: %w", id, err)
+	}
+	return collectionID, nil
+}
+
+// RebuildOptions configures RebuildAlias.
+type RebuildOptions struct {
+
+	// (optional) Prepended to the generated ID of the new collection
+	Prefix string
+
+	// Delete the collection the alias pointed at before, once it is flipped.
+	DeleteOld bool
+}
+
+// RebuildResponse contains the result of RebuildAlias.
+type RebuildResponse struct {
+	Alias string
+
+	// The new collection the alias now points at
+	CollectionID string
+
+	// The collection the alias pointed at before, empty if it was new
+	Previous string
+
+	// Whether the previous collection was deleted
+	Deleted bool
+}
+
+// RebuildAlias rebuilds the collection behind an alias without exposing a
+// half-populated collection. A fresh collection named with GenerateID is
+// created and filled by build; only once build succeeds is the alias
+// flipped to it. If build fails, the new collection is deleted and the
+// alias is left untouched.
+//
+// RebuildAlias requires an alias store, see WithAliasStore.
+func (c *ragClient) RebuildAlias(ctx context.Context, alias string, build func(ctx context.Context, collectionID string) error, opts RebuildOptions, callOpts ...CallOption) (RebuildResponse, error) {
+	ctx, cancel := withCallOptions(ctx, callOpts)
+	defer cancel()
+
+	if c.client.aliases == nil {
+		return RebuildResponse{}, errors.New("rebuilding an alias requires an alias store")
+	}
+	v := newValidator()
+	v.check(alias != "", "alias", "alias should not be empty")
+	v.check(build != nil, "build", "build should not be nil")
+	if !v.valid() {
+		return RebuildResponse{}, *newValidationError("Validation Error", v.errors)
+	}
+
+	// Aliases are stored with full IDs, so that namespaces do not share them
+	fullAlias, err := c.qualify(alias)
+	if err != nil {
+		return RebuildResponse{}, err
+	}
+
+	id, err := GenerateID()
+	if err != nil {
+		return RebuildResponse{}, err
+	}
+	collectionID := opts.Prefix + id
+	fullID, err := c.qualify(collectionID)
+	if err != nil {
+		return RebuildResponse{}, err
+	}
+
+	if _, err := c.CreateCollection(ctx, collectionID); err != nil {
+		return RebuildResponse{}, err
+	}
+	if err := build(ctx, collectionID); err != nil {
+		if _, delErr := c.DeleteCollection(ctx, collectionID); delErr != nil {
+			err = errors.Join(err, fmt.Errorf("delete collection %s: %w", collectionID, delErr))
+		}
+		return RebuildResponse{}, fmt.Errorf("build collection %s: %w", collectionID, err)
+	}
+
+	previous, err := c.client.aliases.Set(ctx, fullAlias, fullID)
+	if err != nil {
+		return RebuildResponse{}, fmt.Errorf("set alias %s: %w", alias, err)
+	}
+	response := RebuildResponse{Alias: alias, CollectionID: collectionID, Previous: c.strip(previous)}
+
+	if opts.DeleteOld && response.Previous != "" && response.Previous != collectionID {
+		if _, err := c.DeleteCollection(ctx, response.Previous); err != nil {
+			return response, fmt.Errorf("delete collection %s: %w", response.Previous, err)
+		}
+		response.Deleted = true
+	}
+	return response, nil
+}
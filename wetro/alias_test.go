@@ -0,0 +1,158 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAliasStores(t *testing.T) {
+	ctx := context.Background()
+	fileStore, err := NewFileAliasStore(filepath.Join(t.TempDir(), "state", "aliases.json"))
+	if err != nil {
+		t.Fatalf("NewFileAliasStore failed: %v", err)
+	}
+
+	for name, store := range map[string]AliasStore{
+		"Memory": NewMemoryAliasStore(),
+		"File":   fileStore,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := store.Resolve(ctx, "docs"); !errors.Is(err, ErrAliasNotFound) {
+				t.Errorf("Expected ErrAliasNotFound, got %v", err)
+			}
+			if previous, _ := store.Set(ctx, "docs", "c1"); previous != "" {
+				t.Errorf("Expected no previous collection, got %q", previous)
+			}
+			if previous, _ := store.Set(ctx, "docs", "c2"); previous != "c1" {
+				t.Errorf("Expected c1 as previous collection, got %q", previous)
+			}
+			if id, err := store.Resolve(ctx, "docs"); err != nil || id != "c2" {
+				t.Errorf("Expected c2, got %q, %v", id, err)
+			}
+			if aliases, err := store.(AliasLister).List(ctx); err != nil || len(aliases) != 1 || aliases["docs"] != "c2" {
+				t.Errorf("Expected docs to be listed, got %v, %v", aliases, err)
+			}
+			store.Delete(ctx, "docs")
+			if _, err := store.Resolve(ctx, "docs"); !errors.Is(err, ErrAliasNotFound) {
+				t.Errorf("Expected the alias to be deleted, got %v", err)
+			}
+		})
+	}
+
+	reopened, _ := NewFileAliasStore(fileStore.path)
+	fileStore.Set(ctx, "kept", "c3")
+	if id, _ := reopened.Resolve(ctx, "kept"); id != "c3" {
+		t.Errorf("Expected the alias to be persisted, got %q", id)
+	}
+
+	// Stores of their own stand in for separate processes
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			store, _ := NewFileAliasStore(fileStore.path)
+			store.Set(ctx, fmt.Sprintf("alias-%d", i), "c")
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < 8; i++ {
+		if _, err := reopened.Resolve(ctx, fmt.Sprintf("alias-%d", i)); err != nil {
+			t.Errorf("Expected alias-%d to be kept, got %v", i, err)
+		}
+	}
+}
+
+func TestRebuildAlias(t *testing.T) {
+	var mu sync.Mutex
+	var created, deleted, queried []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var req map[string]any
+		json.NewDecoder(r.Body).Decode(&req)
+		id, _ := req["collection_id"].(string)
+
+		switch r.URL.Path {
+		case "/v1/collection/create/":
+			created = append(created, id)
+			json.NewEncoder(w).Encode(CollectionCreateResponse{Success: true, CollectionID: id})
+		case "/v1/collection/delete/":
+			deleted = append(deleted, id)
+			json.NewEncoder(w).Encode(DeleteCollectionResponse{Success: true})
+		case "/v1/collection/query/", "/v1/collection/chat/":
+			queried = append(queried, id)
+			json.NewEncoder(w).Encode(StandardResponse{Success: true, Response: "ok"})
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	store := NewMemoryAliasStore()
+	client := NewClient("test-api-key", WithAliasStore(store), func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+	ctx := context.Background()
+
+	first, err := client.RAG.RebuildAlias(ctx, "docs-current", func(ctx context.Context, collectionID string) error {
+		return nil
+	}, RebuildOptions{Prefix: "docs-"})
+	if err != nil {
+		t.Fatalf("RebuildAlias failed: %v", err)
+	}
+	if !strings.HasPrefix(first.CollectionID, "docs-") || first.Previous != "" || created[0] != first.CollectionID {
+		t.Errorf("Unexpected first rebuild %+v", first)
+	}
+
+	client.RAG.QueryCollection(ctx, QueryRequest{CollectionID: "docs-current", Query: "q"})
+	client.RAG.ChatWithCollection(ctx, ChatRequest{CollectionID: "docs-current", Message: "m"})
+	client.RAG.QueryCollection(ctx, QueryRequest{CollectionID: "plain", Query: "q"})
+	if queried[0] != first.CollectionID || queried[1] != first.CollectionID || queried[2] != "plain" {
+		t.Errorf("Expected the alias to be resolved, got %v", queried)
+	}
+
+	t.Run("FailedBuild", func(t *testing.T) {
+		_, err := client.RAG.RebuildAlias(ctx, "docs-current", func(ctx context.Context, collectionID string) error {
+			return errors.New("ingest failed")
+		}, RebuildOptions{DeleteOld: true})
+		if err == nil || !strings.Contains(err.Error(), "ingest failed") {
+			t.Fatalf("Expected the build error, got %v", err)
+		}
+		if id, _ := store.Resolve(ctx, "docs-current"); id != first.CollectionID {
+			t.Errorf("Expected the alias to be left alone, got %q", id)
+		}
+		if len(deleted) != 1 || deleted[0] != created[1] {
+			t.Errorf("Expected the half-built collection to be deleted, got %v", deleted)
+		}
+	})
+
+	t.Run("Flip", func(t *testing.T) {
+		var built string
+		second, err := client.RAG.RebuildAlias(ctx, "docs-current", func(ctx context.Context, collectionID string) error {
+			built = collectionID
+			return nil
+		}, RebuildOptions{DeleteOld: true})
+		if err != nil {
+			t.Fatalf("RebuildAlias failed: %v", err)
+		}
+		if second.CollectionID != built || second.Previous != first.CollectionID || !second.Deleted {
+			t.Errorf("Unexpected rebuild %+v", second)
+		}
+		if deleted[len(deleted)-1] != first.CollectionID {
+			t.Errorf("Expected the old collection to be deleted, got %v", deleted)
+		}
+		if id, _ := store.Resolve(ctx, "docs-current"); id != built {
+			t.Errorf("Expected the alias to point at the new collection, got %q", id)
+		}
+	})
+}
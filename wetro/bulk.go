@@ -0,0 +1,317 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBulkConcurrency  = 4
+	defaultBulkMaxRetries   = 3
+	defaultBulkRetryBackoff = 500 * time.Millisecond
+)
+
+// Resource describes a single resource to be inserted into a collection.
+type Resource struct {
+
+	// The resource itself: a file path, a URL, raw content or an io.Reader
+	Source any
+
+	// The type of the resource
+	Type ResourceType
+}
+
+// BulkInsertOptions controls how InsertResources spreads work over the API.
+type BulkInsertOptions struct {
+
+	// Maximum number of inserts running at the same time. Defaults to 4.
+	Concurrency int
+
+	// Maximum number of inserts started per second. Zero means no limit.
+	RateLimit float64
+
+	// Number of times an insert is retried after a 429 (Too Many Requests)
+	// response. Defaults to 3, a negative value disables retries. Ignored
+	// when the retry policy of the client or call already retries 429
+	// responses, see WithRetryPolicy.
+	MaxRetries int
+
+	// Backoff before the first retry, doubled on every further attempt.
+	// Defaults to 500ms.
+	RetryBackoff time.Duration
+
+	// (optional) Called from the worker goroutines as each resource completes.
+	OnResult func(BulkInsertResult)
+}
+
+func (o BulkInsertOptions) withDefaults() BulkInsertOptions {
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultBulkConcurrency
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = defaultBulkMaxRetries
+	}
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = defaultBulkRetryBackoff
+	}
+	return o
+}
+
+// BulkInsertResult is the outcome of inserting a single resource.
+type BulkInsertResult struct {
+
+	// Position of the resource in the slice passed to InsertResources
+	Index int
+
+	// The ID of the inserted resource, empty on failure
+	ResourceID string
+
+	// Tokens consumed by the insert
+	Tokens int
+
+	// Number of attempts made, including retries
+	Attempts int
+
+	// The error that made the insert fail, nil on success
+	Err error
+}
+
+// BulkInsertSummary aggregates the results of a bulk insert.
+type BulkInsertSummary struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	Tokens    int
+	Duration  time.Duration
+}
+
+// BulkInsertResponse contains the per-resource results of a bulk insert,
+// in the same order as the input, together with an aggregate summary.
+type BulkInsertResponse struct {
+	Results []BulkInsertResult
+	Summary BulkInsertSummary
+}
+
+// Failed returns the results of the resources that could not be inserted.
+func (r BulkInsertResponse) Failed() []BulkInsertResult {
+	var failed []BulkInsertResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// InsertResources inserts many resources into a collection through a bounded
+// pool of workers. A failing resource does not stop the others; its error is
+// reported in the matching BulkInsertResult.
+func (c *ragClient) InsertResources(ctx context.Context, collectionID string, resources []Resource, opts BulkInsertOptions, callOpts ...CallOption) (BulkInsertResponse, error) {
+	ctx, cancel := withCallOptions(ctx, callOpts)
+	defer cancel()
+
+	v := newValidator()
+	v.check(collectionID != "", "collection_id", "collection_id should not be empty")
+	for _, resource := range resources {
+		v.check(resource.Source != nil, "resources", "resource source should not be nil")
+		v.check(resource.Type != "", "type", "resource type should not be empty")
+	}
+	if !v.valid() {
+		return BulkInsertResponse{}, *newValidationError("Validation Error", v.errors)
+	}
+
+	opts = opts.withDefaults()
+	limiter := newRateLimiter(opts.RateLimit)
+	start := time.Now()
+
+	results := make([]BulkInsertResult, len(resources))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Concurrency && w < len(resources); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = c.insertWithRetry(ctx, collectionID, i, resources[i], limiter, opts)
+				results[i].Index = i
+				if opts.OnResult != nil {
+					opts.OnResult(results[i])
+				}
+			}
+		}()
+	}
+
+	for i := range resources {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	response := BulkInsertResponse{
+		Results: results,
+		Summary: BulkInsertSummary{
+			Total:    len(resources),
+			Duration: time.Since(start),
+		},
+	}
+	for _, result := range results {
+		response.Summary.Tokens += result.Tokens
+		if result.Err != nil {
+			response.Summary.Failed++
+		} else {
+			response.Summary.Succeeded++
+		}
+	}
+	return response, nil
+}
+
+func (c *ragClient) insertWithRetry(ctx context.Context, collectionID string, index int, resource Resource, limiter *rateLimiter, opts BulkInsertOptions) BulkInsertResult {
+	var result BulkInsertResult
+	backoff := opts.RetryBackoff
+
+	// Retries are left to the retry policy when it covers 429 responses,
+	// rather than multiplying its attempts
+	maxRetries := opts.MaxRetries
+	if c.client.retryPolicy(callOptionsFrom(ctx)).retries(http.StatusTooManyRequests) {
+		maxRetries = 0
+	}
+
+	source := resource.Source
+	rewind := func() error { return nil }
+	if maxRetries > 0 {
+		var err error
+		if source, rewind, err = replayable(source); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	// One key per resource, reused by the retries below
+	keyOpts := idempotencyOptions(ctx, strconv.Itoa(index))
+	if keyOpts == nil {
+		key, err := GenerateID()
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		keyOpts = []CallOption{WithIdempotencyKey(key)}
+	}
+
+	for {
+		if err := limiter.wait(ctx); err != nil {
+			result.Err = err
+			return result
+		}
+
+		if result.Attempts > 0 {
+			if err := rewind(); err != nil {
+				result.Err = err
+				return result
+			}
+		}
+		result.Attempts++
+		resp, err := c.InsertResource(ctx, collectionID, source, resource.Type, keyOpts...)
+		if err == nil {
+			result.ResourceID = resp.ResourceID
+			result.Tokens = resp.Tokens
+			result.Err = nil
+			return result
+		}
+		result.Err = err
+
+		if !isRateLimited(err) || result.Attempts > maxRetries {
+			return result
+		}
+
+		if err := sleep(ctx, backoff); err != nil {
+			result.Err = err
+			return result
+		}
+		backoff *= 2
+	}
+}
+
+// replayable returns a source that can be inserted again after a failed
+// attempt, along with the function that rewinds it. Readers that can seek
+// are rewound to where they started; other readers are read into memory.
+func replayable(source any) (any, func() error, error) {
+	switch r := source.(type) {
+	case io.ReadSeeker:
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, nil, err
+		}
+		return r, func() error {
+			_, err := r.Seek(offset, io.SeekStart)
+			return err
+		}, nil
+	case io.Reader:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		reader := bytes.NewReader(data)
+		return reader, func() error {
+			_, err := reader.Seek(0, io.SeekStart)
+			return err
+		}, nil
+	}
+	return source, func() error { return nil }, nil
+}
+
+func isRateLimited(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests
+}
+
+// rateLimiter spaces out operations so that at most rate of them start per second.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(rate float64) *rateLimiter {
+	l := &rateLimiter{}
+	if rate > 0 {
+		l.interval = time.Duration(float64(time.Second) / rate)
+	}
+	return l
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if l.interval == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	return sleep(ctx, delay)
+}
@@ -0,0 +1,214 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInsertResources(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts = map[string]int{}
+		inFlight int32
+		maxSeen  int32
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/resource/insert/" {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxSeen)
+			if n <= seen || atomic.CompareAndSwapInt32(&maxSeen, seen, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		var req ResourceInsertRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		mu.Lock()
+		attempts[req.Resource]++
+		attempt := attempts[req.Resource]
+		mu.Unlock()
+
+		switch req.Resource {
+		case "https://example.com/throttled":
+			if attempt == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				json.NewEncoder(w).Encode(map[string]string{"error": "slow down"})
+				return
+			}
+		case "https://example.com/broken":
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "bad resource"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(ResourceInsertResponse{
+			Success:    true,
+			ResourceID: "id-" + req.Resource[len("https://example.com/"):],
+			Tokens:     3,
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+
+	ctx := context.Background()
+
+	t.Run("PerItemResults", func(t *testing.T) {
+		resources := []Resource{
+			{Source: "https://example.com/a", Type: ResourceTypeWeb},
+			{Source: "https://example.com/throttled", Type: ResourceTypeWeb},
+			{Source: "https://example.com/broken", Type: ResourceTypeWeb},
+			{Source: "https://example.com/b", Type: ResourceTypeWeb},
+			{Source: "https://example.com/c", Type: ResourceTypeWeb},
+		}
+
+		var callbacks int32
+		resp, err := client.RAG.InsertResources(ctx, "test-collection", resources, BulkInsertOptions{
+			Concurrency:  2,
+			RetryBackoff: time.Millisecond,
+			OnResult: func(BulkInsertResult) {
+				atomic.AddInt32(&callbacks, 1)
+			},
+		})
+		if err != nil {
+			t.Fatalf("InsertResources failed: %v", err)
+		}
+
+		if resp.Summary.Total != 5 || resp.Summary.Succeeded != 4 || resp.Summary.Failed != 1 {
+			t.Errorf("Unexpected summary %+v", resp.Summary)
+		}
+		if resp.Summary.Tokens != 12 {
+			t.Errorf("Expected 12 tokens, got %d", resp.Summary.Tokens)
+		}
+		if callbacks != 5 {
+			t.Errorf("Expected 5 callbacks, got %d", callbacks)
+		}
+		if resp.Results[1].ResourceID != "id-throttled" || resp.Results[1].Attempts != 2 {
+			t.Errorf("Expected throttled resource to succeed on retry, got %+v", resp.Results[1])
+		}
+		if failed := resp.Failed(); len(failed) != 1 || failed[0].Index != 2 {
+			t.Errorf("Expected resource 2 to fail, got %+v", failed)
+		}
+		if resp.Results[3].ResourceID != "id-b" {
+			t.Errorf("Expected results in input order, got %+v", resp.Results[3])
+		}
+		if maxSeen > 2 {
+			t.Errorf("Expected at most 2 concurrent inserts, saw %d", maxSeen)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		_, err := client.RAG.InsertResources(ctx, "", []Resource{{Source: "x", Type: ResourceTypeText}}, BulkInsertOptions{})
+		if _, ok := err.(ValidationError); !ok {
+			t.Fatalf("Expected ValidationError, got %v", err)
+		}
+	})
+
+	t.Run("RateLimit", func(t *testing.T) {
+		resources := make([]Resource, 4)
+		for i := range resources {
+			resources[i] = Resource{Source: "https://example.com/r", Type: ResourceTypeWeb}
+		}
+
+		start := time.Now()
+		_, err := client.RAG.InsertResources(ctx, "test-collection", resources, BulkInsertOptions{
+			Concurrency: 4,
+			RateLimit:   50,
+		})
+		if err != nil {
+			t.Fatalf("InsertResources failed: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+			t.Errorf("Expected rate limit to spread 4 inserts over at least 60ms, took %v", elapsed)
+		}
+	})
+}
+
+func TestInsertResourcesRetry(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		uploads []string
+		inserts int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/upload/":
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(file)
+			uploads = append(uploads, string(data))
+			json.NewEncoder(w).Encode(map[string]string{"url": "https://files.example.com/f"})
+		case "/v1/resource/insert/":
+			inserts++
+			if inserts == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				json.NewEncoder(w).Encode(map[string]string{"detail": "Slow down."})
+				return
+			}
+			json.NewEncoder(w).Encode(ResourceInsertResponse{Success: true, ResourceID: "r1"})
+		}
+	}))
+	defer server.Close()
+
+	newClient := func(opts ...ClientOption) *Client {
+		return NewClient("test-api-key", append(opts, func(c *apiClient) {
+			c.baseURL = server.URL + "/"
+			c.uploadURL = server.URL + "/upload/"
+		})...)
+	}
+	ctx := context.Background()
+	opts := BulkInsertOptions{RetryBackoff: time.Millisecond}
+
+	sources := map[string]func() io.Reader{
+		"Reader":     func() io.Reader { return io.MultiReader(strings.NewReader("hello")) },
+		"ReadSeeker": func() io.Reader { return strings.NewReader("hello") },
+	}
+	for name, source := range sources {
+		t.Run(name, func(t *testing.T) {
+			uploads, inserts = nil, 0
+			resp, err := newClient().RAG.InsertResources(ctx, "docs", []Resource{{Source: source(), Type: ResourceTypeFile}}, opts)
+			if err != nil || resp.Summary.Succeeded != 1 {
+				t.Fatalf("Expected the insert to succeed on retry, got %+v, %v", resp.Results, err)
+			}
+			if len(uploads) != 2 || uploads[0] != "hello" || uploads[1] != "hello" {
+				t.Errorf("Expected the retry to upload the content again, got %q", uploads)
+			}
+		})
+	}
+
+	t.Run("RetryPolicy", func(t *testing.T) {
+		uploads, inserts = nil, 0
+		client := newClient(WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}))
+		resp, err := client.RAG.InsertResources(ctx, "docs", []Resource{{Source: "text", Type: ResourceTypeText}}, opts)
+		if err != nil || resp.Summary.Succeeded != 1 {
+			t.Fatalf("Expected the insert to succeed on retry, got %+v, %v", resp.Results, err)
+		}
+		if inserts != 2 || resp.Results[0].Attempts != 1 {
+			t.Errorf("Expected the client policy alone to retry, got %d requests over %d attempts", inserts, resp.Results[0].Attempts)
+		}
+	})
+}
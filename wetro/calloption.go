@@ -0,0 +1,151 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CallOption changes how a single method call is made, without building a
+// new Client. Options passed to a method that makes several requests, such
+// as IngestDirectory, apply to each of them.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	timeout        time.Duration
+	headers        http.Header
+	idempotencyKey string
+	retry          *RetryPolicy
+	model          ChatModel
+	responseHeader *http.Header
+	responseMeta   *ResponseMeta
+
+	// Guards responseHeader and responseMeta, which the concurrent requests
+	// of a call write to
+	captureMu *sync.Mutex
+}
+
+// WithTimeout bounds the call, including its retries, by d.
+func WithTimeout(d time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = d
+	}
+}
+
+// WithHeader adds a header to the requests of the call. It cannot replace
+// the Authorization header.
+func WithHeader(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.headers == nil {
+			o.headers = make(http.Header)
+		}
+		o.headers.Add(key, value)
+	}
+}
+
+// WithIdempotencyKey sends key in the Idempotency-Key header, so that the
+// API can recognize a retried request. The key applies to the call it is
+// passed to and is not inherited by the calls it makes; methods inserting
+// several resources, such as InsertResources, derive a key per resource
+// from it.
+func WithIdempotencyKey(key string) CallOption {
+	return func(o *callOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// WithCallRetryPolicy replaces the retry policy of the client for the call,
+// see WithRetryPolicy.
+func WithCallRetryPolicy(policy RetryPolicy) CallOption {
+	return func(o *callOptions) {
+		o.retry = &policy
+	}
+}
+
+// WithModel replaces the model of the request, for calls that select one
+// such as QueryCollection and GenerateText.
+func WithModel(model ChatModel) CallOption {
+	return func(o *callOptions) {
+		o.model = model
+	}
+}
+
+// WithResponseHeader stores the headers of the response in h once the call
+// returns, including for error responses. For calls making several
+// requests, h holds the headers of the last one to complete.
+func WithResponseHeader(h *http.Header) CallOption {
+	return func(o *callOptions) {
+		o.responseHeader = h
+		if o.captureMu == nil {
+			o.captureMu = new(sync.Mutex)
+		}
+	}
+}
+
+// capture records the outcome of a request for WithResponseHeader and
+// WithResponseMeta.
+func (o callOptions) capture(resp *http.Response, latency time.Duration, attempts int) {
+	if o.responseHeader == nil && o.responseMeta == nil {
+		return
+	}
+	o.captureMu.Lock()
+	defer o.captureMu.Unlock()
+	if resp != nil && o.responseHeader != nil {
+		*o.responseHeader = resp.Header.Clone()
+	}
+	if o.responseMeta != nil {
+		*o.responseMeta = newResponseMeta(resp, latency, attempts)
+	}
+}
+
+type callOptionsKey struct{}
+
+// withCallOptions returns a context carrying opts on top of the options of
+// an enclosing call, and bounded by their timeout. The context is passed
+// down to doRequest and to nested method calls. The idempotency key of an
+// enclosing call is dropped, see idempotencyOptions.
+func withCallOptions(ctx context.Context, opts []CallOption) (context.Context, context.CancelFunc) {
+	o := callOptionsFrom(ctx)
+	if len(opts) == 0 && o.idempotencyKey == "" {
+		return ctx, func() {}
+	}
+
+	o.timeout = 0
+	o.idempotencyKey = ""
+	if o.headers != nil {
+		o.headers = o.headers.Clone()
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx = context.WithValue(ctx, callOptionsKey{}, o)
+	if o.timeout > 0 {
+		return context.WithTimeout(ctx, o.timeout)
+	}
+	return ctx, func() {}
+}
+
+// idempotencyOptions passes the idempotency key of the current call on to
+// a nested call, with suffix appended to tell apart the nested calls of a
+// single logical operation. It returns nil if the call has no key.
+func idempotencyOptions(ctx context.Context, suffix string) []CallOption {
+	key := callOptionsFrom(ctx).idempotencyKey
+	if key == "" {
+		return nil
+	}
+	if suffix != "" {
+		key += "-" + suffix
+	}
+	return []CallOption{WithIdempotencyKey(key)}
+}
+
+func callOptionsFrom(ctx context.Context) callOptions {
+	o, _ := ctx.Value(callOptionsKey{}).(callOptions)
+	return o
+}
@@ -0,0 +1,124 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCallOptions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("HeadersAndModel", func(t *testing.T) {
+		var got *http.Request
+		var model ChatModel
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			var request QueryRequest
+			json.NewDecoder(r.Body).Decode(&request)
+			model = request.Model
+			w.Header().Set("X-Request-Id", "req-1")
+			json.NewEncoder(w).Encode(StandardResponse{Success: true})
+		}))
+		defer server.Close()
+
+		client := NewClient("test-api-key", func(c *apiClient) {
+			c.baseURL = server.URL + "/"
+		})
+		var header http.Header
+		_, err := client.RAG.QueryCollection(ctx, QueryRequest{CollectionID: "docs", Query: "q", Model: GPT4O},
+			WithHeader("X-Trace", "abc"),
+			WithHeader("Authorization", "Token other"),
+			WithIdempotencyKey("key-1"),
+			WithModel(GPT4OMini),
+			WithResponseHeader(&header),
+		)
+		if err != nil {
+			t.Fatalf("QueryCollection failed: %v", err)
+		}
+		if got.Header.Get("X-Trace") != "abc" || got.Header.Get("Idempotency-Key") != "key-1" {
+			t.Errorf("Expected the call headers to be sent, got %v", got.Header)
+		}
+		if got.Header.Get("Authorization") != "Token test-api-key" {
+			t.Errorf("Expected the Authorization header to be kept, got %q", got.Header.Get("Authorization"))
+		}
+		if model != GPT4OMini {
+			t.Errorf("Expected the model to be overridden, got %q", model)
+		}
+		if header.Get("X-Request-Id") != "req-1" {
+			t.Errorf("Expected the response headers to be captured, got %v", header)
+		}
+
+		// Options do not leak into later calls
+		client.RAG.QueryCollection(ctx, QueryRequest{CollectionID: "docs", Query: "q", Model: GPT4O})
+		if got.Header.Get("X-Trace") != "" || model != GPT4O {
+			t.Error("Expected the options to apply to a single call")
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer server.Close()
+
+		client := NewClient("test-api-key", func(c *apiClient) {
+			c.baseURL = server.URL + "/"
+		})
+		_, err := client.RAG.GetCollection(ctx, "docs", WithTimeout(20*time.Millisecond))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected a deadline error, got %v", err)
+		}
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1)%3 != 0 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				json.NewEncoder(w).Encode(map[string]string{"detail": "Try again."})
+				return
+			}
+			json.NewEncoder(w).Encode(GetCollectionResponse{Success: true, Found: true})
+		}))
+		defer server.Close()
+
+		client := NewClient("test-api-key", WithRetryPolicy(RetryPolicy{MaxAttempts: 3}), func(c *apiClient) {
+			c.baseURL = server.URL + "/"
+		})
+		if _, err := client.RAG.GetCollection(ctx, "docs"); err != nil {
+			t.Fatalf("Expected the call to succeed after retries, got %v", err)
+		}
+		if requests.Load() != 3 {
+			t.Errorf("Expected 3 requests, got %d", requests.Load())
+		}
+
+		requests.Store(0)
+		_, err := client.RAG.GetCollection(ctx, "docs", WithCallRetryPolicy(RetryPolicy{}))
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("Expected a 503 APIError, got %v", err)
+		}
+		if requests.Load() != 1 {
+			t.Errorf("Expected retries to be disabled for the call, got %d requests", requests.Load())
+		}
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+		for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: time.Second} {
+			if got := policy.backoff(attempt, nil); got != want {
+				t.Errorf("backoff(%d) = %v, want %v", attempt, got, want)
+			}
+		}
+	})
+}
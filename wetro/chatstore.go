@@ -0,0 +1,245 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrSessionNotFound is returned by a ChatStore for an unknown session ID.
+var ErrSessionNotFound = errors.New("chat session not found")
+
+// ChatStore persists chat histories by session ID, so chat sessions can be
+// resumed from any process.
+type ChatStore interface {
+
+	// Load returns the history of a session, or ErrSessionNotFound.
+	Load(ctx context.Context, sessionID string) ([]Message, error)
+
+	// Append adds messages to the end of a session, creating it if needed.
+	Append(ctx context.Context, sessionID string, messages ...Message) error
+
+	// Trim drops all but the keep most recent messages of a session.
+	Trim(ctx context.Context, sessionID string, keep int) error
+
+	// Delete removes a session. Deleting an unknown session is not an error.
+	Delete(ctx context.Context, sessionID string) error
+}
+
+// MemoryChatStore is a ChatStore that keeps histories in memory.
+// It is safe for concurrent use.
+type MemoryChatStore struct {
+	mu       sync.Mutex
+	sessions map[string][]Message
+}
+
+// NewMemoryChatStore returns an empty in-memory chat store.
+func NewMemoryChatStore() *MemoryChatStore {
+	return &MemoryChatStore{sessions: make(map[string][]Message)}
+}
+
+// Load implements ChatStore.
+func (s *MemoryChatStore) Load(ctx context.Context, sessionID string) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	history, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return copyHistory(history), nil
+}
+
+// Append implements ChatStore.
+func (s *MemoryChatStore) Append(ctx context.Context, sessionID string, messages ...Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[sessionID] = append(s.sessions[sessionID], copyHistory(messages)...)
+	return nil
+}
+
+// Trim implements ChatStore.
+func (s *MemoryChatStore) Trim(ctx context.Context, sessionID string, keep int) error {
+	if err := validateKeep(keep); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	history, ok := s.sessions[sessionID]
+	if !ok {
+		return ErrSessionNotFound
+	}
+	if keep < len(history) {
+		s.sessions[sessionID] = append([]Message{}, history[len(history)-keep:]...)
+	}
+	return nil
+}
+
+// Delete implements ChatStore.
+func (s *MemoryChatStore) Delete(ctx context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sessionID)
+	return nil
+}
+
+// FileChatStore is a ChatStore that keeps every session in its own JSON
+// Lines file under a directory, one message per line. Reads and writes take
+// a lock on the directory, so several processes can share it.
+type FileChatStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileChatStore returns a chat store writing to dir, creating it if needed.
+func NewFileChatStore(dir string) (*FileChatStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileChatStore{dir: dir}, nil
+}
+
+func (s *FileChatStore) path(sessionID string) (string, error) {
+	if sessionID == "" || sessionID == "." || sessionID == ".." {
+		return "", fmt.Errorf("invalid session ID %q", sessionID)
+	}
+	return filepath.Join(s.dir, url.PathEscape(sessionID)+".jsonl"), nil
+}
+
+// withLock runs fn under the lock of the store directory, shared by every
+// process using it.
+func (s *FileChatStore) withLock(fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return withFileLock(filepath.Join(s.dir, ".lock"), fn)
+}
+
+// Load implements ChatStore.
+func (s *FileChatStore) Load(ctx context.Context, sessionID string) ([]Message, error) {
+	path, err := s.path(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	var history []Message
+	err = s.withLock(func() error {
+		history, err = s.load(path)
+		return err
+	})
+	return history, err
+}
+
+func (s *FileChatStore) load(path string) ([]Message, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrSessionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	history := []Message{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var m Message
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		history = append(history, m)
+	}
+	return history, scanner.Err()
+}
+
+// Append implements ChatStore.
+func (s *FileChatStore) Append(ctx context.Context, sessionID string, messages ...Message) error {
+	path, err := s.path(sessionID)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, m := range messages {
+		if err := enc.Encode(m); err != nil {
+			return err
+		}
+	}
+
+	return s.withLock(func() error {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(buf.Bytes()); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	})
+}
+
+// Trim implements ChatStore.
+func (s *FileChatStore) Trim(ctx context.Context, sessionID string, keep int) error {
+	if err := validateKeep(keep); err != nil {
+		return err
+	}
+	path, err := s.path(sessionID)
+	if err != nil {
+		return err
+	}
+
+	return s.withLock(func() error {
+		history, err := s.load(path)
+		if err != nil {
+			return err
+		}
+		if keep >= len(history) {
+			return nil
+		}
+
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		for _, m := range history[len(history)-keep:] {
+			if err := enc.Encode(m); err != nil {
+				return err
+			}
+		}
+		return writeFileAtomic(path, buf.Bytes(), 0o644)
+	})
+}
+
+// Delete implements ChatStore.
+func (s *FileChatStore) Delete(ctx context.Context, sessionID string) error {
+	path, err := s.path(sessionID)
+	if err != nil {
+		return err
+	}
+	return s.withLock(func() error {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+}
+
+func validateKeep(keep int) error {
+	v := newValidator()
+	v.check(keep >= 0, "keep", "keep should not be negative")
+	if !v.valid() {
+		return *newValidationError("Validation Error", v.errors)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package wetro
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestChatStores(t *testing.T) {
+	fileStore, err := NewFileChatStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileChatStore failed: %v", err)
+	}
+
+	stores := map[string]ChatStore{
+		"Memory": NewMemoryChatStore(),
+		"File":   fileStore,
+	}
+	ctx := context.Background()
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if _, err := store.Load(ctx, "user/42"); !errors.Is(err, ErrSessionNotFound) {
+				t.Fatalf("Expected ErrSessionNotFound, got %v", err)
+			}
+
+			err := store.Append(ctx, "user/42",
+				Message{"role": "user", "content": "hi"},
+				Message{"role": "assistant", "content": "hello\nthere"},
+			)
+			if err != nil {
+				t.Fatalf("Append failed: %v", err)
+			}
+			store.Append(ctx, "user/42", Message{"role": "user", "content": "bye"})
+
+			history, err := store.Load(ctx, "user/42")
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if len(history) != 3 || history[1]["content"] != "hello\nthere" {
+				t.Errorf("Unexpected history %v", history)
+			}
+
+			if _, ok := store.Trim(ctx, "user/42", -1).(ValidationError); !ok {
+				t.Error("Expected a ValidationError for a negative keep")
+			}
+			if err := store.Trim(ctx, "user/42", 1); err != nil {
+				t.Fatalf("Trim failed: %v", err)
+			}
+			history, _ = store.Load(ctx, "user/42")
+			if len(history) != 1 || history[0]["content"] != "bye" {
+				t.Errorf("Expected only the last message to be kept, got %v", history)
+			}
+
+			if err := store.Delete(ctx, "user/42"); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+			if _, err := store.Load(ctx, "user/42"); !errors.Is(err, ErrSessionNotFound) {
+				t.Errorf("Expected deleted session to be gone, got %v", err)
+			}
+			if err := store.Delete(ctx, "user/42"); err != nil {
+				t.Errorf("Expected deleting an unknown session to succeed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFileChatStoreShared(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := NewFileChatStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trimmer, err := NewFileChatStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// Stores of their own stand in for separate processes
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			writer.Append(ctx, "s", Message{"content": strconv.Itoa(i)})
+		}
+	}()
+	for trimming := true; trimming; {
+		select {
+		case <-done:
+			trimming = false
+		default:
+		}
+		trimmer.Trim(ctx, "s", 10)
+	}
+
+	history, err := writer.Load(ctx, "s")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(history) != 10 {
+		t.Fatalf("Expected 10 messages, got %d", len(history))
+	}
+	for i, m := range history {
+		if m["content"] != strconv.Itoa(40+i) {
+			t.Fatalf("Expected no append to be lost, got %v", history)
+		}
+	}
+}
+
+func TestChatSessionPersistence(t *testing.T) {
+	var last ChatRequest
+	client := newChatServer(t, func(req ChatRequest) { last = req })
+	ctx := context.Background()
+
+	store, err := NewFileChatStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := ChatSessionOptions{Store: store, SessionID: "session-1", MaxStoredMessages: 4}
+
+	session := client.RAG.NewChatSession("docs", opts)
+	for _, msg := range []string{"one", "two", "three"} {
+		if _, err := session.Send(ctx, msg); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+
+	// A different process resumes the session from the same store.
+	resumed, err := client.RAG.ResumeChatSession(ctx, "docs", opts)
+	if err != nil {
+		t.Fatalf("ResumeChatSession failed: %v", err)
+	}
+	if history := resumed.History(); len(history) != 4 || history[0]["content"] != "two" {
+		t.Errorf("Expected the 4 most recent messages, got %v", history)
+	}
+
+	resumed.Send(ctx, "four")
+	if len(last.ChatHistory) != 4 || last.ChatHistory[2]["content"] != "three" {
+		t.Errorf("Expected the resumed history to be sent, got %v", last.ChatHistory)
+	}
+
+	if err := resumed.Reset(ctx); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if _, err := client.RAG.ResumeChatSession(ctx, "docs", opts); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected reset session to be deleted, got %v", err)
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Chunker splits text into pieces small enough to be inserted as separate
+// resources. Sizes are measured in characters (runes).
+type Chunker interface {
+	Chunk(text string) []string
+}
+
+// FixedSizeChunker cuts text into chunks of Size characters, each starting
+// Overlap characters before the end of the previous one. Overlap must be at
+// least 0 and less than Size; InsertChunkedText rejects other values, and
+// Chunk ignores them.
+type FixedSizeChunker struct {
+	Size    int
+	Overlap int
+}
+
+func (c FixedSizeChunker) validate(v *validator) bool {
+	v.check(c.Overlap >= 0 && (c.Size <= 0 || c.Overlap < c.Size), "overlap", "overlap should be at least 0 and less than size")
+	return v.valid()
+}
+
+// Chunk implements Chunker.
+func (c FixedSizeChunker) Chunk(text string) []string {
+	runes := []rune(text)
+	if c.Size <= 0 || len(runes) <= c.Size {
+		return nonEmpty([]string{text})
+	}
+
+	step := c.Size - c.Overlap
+	if step <= 0 || step > c.Size {
+		step = c.Size
+	}
+
+	var chunks []string
+	for start := 0; start < len(runes); start += step {
+		end := start + c.Size
+		if end > len(runes) {
+			end = len(runes)
+		}
+		chunks = append(chunks, string(runes[start:end]))
+		if end == len(runes) {
+			break
+		}
+	}
+	return nonEmpty(chunks)
+}
+
+// SentenceChunker packs whole sentences into chunks of at most MaxSize
+// characters. Sentences longer than MaxSize are cut with a FixedSizeChunker.
+// Each chunk repeats the last Overlap sentences of the previous one.
+// Overlap must not be negative; InsertChunkedText rejects negative values,
+// and Chunk ignores them.
+type SentenceChunker struct {
+	MaxSize int
+	Overlap int
+}
+
+func (c SentenceChunker) validate(v *validator) bool {
+	v.check(c.Overlap >= 0, "overlap", "overlap should not be negative")
+	return v.valid()
+}
+
+// Chunk implements Chunker.
+func (c SentenceChunker) Chunk(text string) []string {
+	return packUnits(splitSentences(text), c.MaxSize, c.Overlap, " ")
+}
+
+// MarkdownChunker splits Markdown along its headings and paragraphs. Every
+// chunk stays within a single section and starts with the heading of that
+// section. Paragraphs that do not fit in MaxSize characters are split into
+// sentences.
+type MarkdownChunker struct {
+	MaxSize int
+}
+
+// Chunk implements Chunker.
+func (c MarkdownChunker) Chunk(text string) []string {
+	var chunks []string
+	for _, section := range splitMarkdownSections(text) {
+		budget := c.MaxSize
+		if section.heading != "" && budget > 0 {
+			budget -= len([]rune(section.heading)) + 2
+			if budget <= 0 {
+				budget = c.MaxSize
+			}
+		}
+
+		var units []string
+		for _, paragraph := range section.paragraphs {
+			if budget > 0 && len([]rune(paragraph)) > budget {
+				units = append(units, SentenceChunker{MaxSize: budget}.Chunk(paragraph)...)
+			} else {
+				units = append(units, paragraph)
+			}
+		}
+
+		for _, chunk := range packUnits(units, budget, 0, "\n\n") {
+			if section.heading != "" {
+				chunk = section.heading + "\n\n" + chunk
+			}
+			chunks = append(chunks, chunk)
+		}
+		if len(units) == 0 && section.heading != "" {
+			chunks = append(chunks, section.heading)
+		}
+	}
+	return chunks
+}
+
+// packUnits greedily joins units with sep into chunks of at most maxSize
+// characters, repeating the last overlap units of a chunk at the start of
+// the next one.
+func packUnits(units []string, maxSize, overlap int, sep string) []string {
+	var chunks []string
+	var current []string
+	size := 0
+
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, strings.Join(current, sep))
+		}
+	}
+
+	for _, unit := range units {
+		n := len([]rune(unit))
+		if maxSize > 0 && n > maxSize {
+			flush()
+			current, size = nil, 0
+			chunks = append(chunks, FixedSizeChunker{Size: maxSize}.Chunk(unit)...)
+			continue
+		}
+
+		if maxSize > 0 && len(current) > 0 && size+len(sep)+n > maxSize {
+			flush()
+			keep := min(max(overlap, 0), len(current))
+			current = append([]string{}, current[len(current)-keep:]...)
+			size = len([]rune(strings.Join(current, sep)))
+			for len(current) > 0 && size+len(sep)+n > maxSize {
+				current = current[1:]
+				size = len([]rune(strings.Join(current, sep)))
+			}
+		}
+
+		if len(current) > 0 {
+			size += len(sep)
+		}
+		current = append(current, unit)
+		size += n
+	}
+	flush()
+	return chunks
+}
+
+// splitSentences breaks text after '.', '!' or '?' followed by whitespace.
+func splitSentences(text string) []string {
+	var sentences []string
+	runes := []rune(text)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '.', '!', '?':
+			if i+1 == len(runes) || unicode.IsSpace(runes[i+1]) {
+				sentences = append(sentences, strings.TrimSpace(string(runes[start:i+1])))
+				start = i + 1
+			}
+		}
+	}
+	sentences = append(sentences, strings.TrimSpace(string(runes[start:])))
+	return nonEmpty(sentences)
+}
+
+type markdownSection struct {
+	heading    string
+	paragraphs []string
+}
+
+// splitMarkdownSections groups Markdown paragraphs under the heading they
+// belong to. Headings inside fenced code blocks are ignored.
+func splitMarkdownSections(text string) []markdownSection {
+	var sections []markdownSection
+	current := markdownSection{}
+	var paragraph []string
+	inFence := false
+
+	endParagraph := func() {
+		if p := strings.TrimSpace(strings.Join(paragraph, "\n")); p != "" {
+			current.paragraphs = append(current.paragraphs, p)
+		}
+		paragraph = nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+
+		switch {
+		case !inFence && strings.HasPrefix(trimmed, "#"):
+			endParagraph()
+			if current.heading != "" || len(current.paragraphs) > 0 {
+				sections = append(sections, current)
+			}
+			current = markdownSection{heading: trimmed}
+		case !inFence && trimmed == "":
+			endParagraph()
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	endParagraph()
+	if current.heading != "" || len(current.paragraphs) > 0 {
+		sections = append(sections, current)
+	}
+	return sections
+}
+
+func nonEmpty(parts []string) []string {
+	out := parts[:0]
+	for _, part := range parts {
+		if strings.TrimSpace(part) != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// TextChunk is a piece of a larger text together with where it came from.
+type TextChunk struct {
+
+	// The source the text came from, such as a file name or URL
+	Source string
+
+	// Position of the chunk within the source, starting from 1
+	Ordinal int
+
+	// Total number of chunks the source was split into
+	Total int
+
+	Text string
+}
+
+// Resource returns the chunk text prefixed with its source and ordinal, as
+// it is inserted into a collection.
+func (c TextChunk) Resource() string {
+	return fmt.Sprintf("[source: %s | chunk %d/%d]\n\n%s", c.Source, c.Ordinal, c.Total, c.Text)
+}
+
+// ChunkText splits text with chunker and tags every chunk with source.
+func ChunkText(source, text string, chunker Chunker) []TextChunk {
+	parts := chunker.Chunk(text)
+	chunks := make([]TextChunk, len(parts))
+	for i, part := range parts {
+		chunks[i] = TextChunk{Source: source, Ordinal: i + 1, Total: len(parts), Text: part}
+	}
+	return chunks
+}
+
+// ChunkGroup holds the resources created from a single chunked text, so
+// they can be removed together.
+type ChunkGroup struct {
+	CollectionID string
+	Source       string
+
+	// IDs of the inserted chunks, in chunk order
+	ResourceIDs []string
+
+	// Total tokens consumed by the inserts
+	Tokens int
+}
+
+// InsertChunkedText splits a large text with chunker and inserts every chunk
+// as its own ResourceTypeText resource. If some chunks fail, the returned
+// group still holds the chunks that were inserted, alongside the error.
+func (c *ragClient) InsertChunkedText(ctx context.Context, collectionID, source, text string, chunker Chunker, opts BulkInsertOptions, callOpts ...CallOption) (ChunkGroup, error) {
+	ctx, cancel := withCallOptions(ctx, callOpts)
+	defer cancel()
+
+	v := newValidator()
+	if checked, ok := chunker.(interface{ validate(*validator) bool }); ok && !checked.validate(v) {
+		return ChunkGroup{}, *newValidationError("Validation Error", v.errors)
+	}
+
+	chunks := ChunkText(source, text, chunker)
+	resources := make([]Resource, len(chunks))
+	for i, chunk := range chunks {
+		resources[i] = Resource{Source: chunk.Resource(), Type: ResourceTypeText}
+	}
+
+	group := ChunkGroup{CollectionID: collectionID, Source: source}
+	bulk, err := c.InsertResources(ctx, collectionID, resources, opts, idempotencyOptions(ctx, "")...)
+	if err != nil {
+		return group, err
+	}
+
+	var errs []error
+	for i, result := range bulk.Results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("chunk %d/%d: %w", chunks[i].Ordinal, chunks[i].Total, result.Err))
+			continue
+		}
+		group.ResourceIDs = append(group.ResourceIDs, result.ResourceID)
+		group.Tokens += result.Tokens
+	}
+	return group, errors.Join(errs...)
+}
+
+// RemoveChunkGroup removes every resource of a chunk group. It keeps going
+// when a removal fails and reports all failures together.
+func (c *ragClient) RemoveChunkGroup(ctx context.Context, group ChunkGroup, opts ...CallOption) error {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
+	var errs []error
+	for _, id := range group.ResourceIDs {
+		_, err := c.RemoveResource(ctx, ResourceDeleteRequest{CollectionID: group.CollectionID, ResourceID: id})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("remove resource %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,162 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestChunkers(t *testing.T) {
+	t.Run("FixedSize", func(t *testing.T) {
+		chunks := FixedSizeChunker{Size: 4, Overlap: 1}.Chunk("abcdefghij")
+		want := []string{"abcd", "defg", "ghij"}
+		if strings.Join(chunks, ",") != strings.Join(want, ",") {
+			t.Errorf("Expected %v, got %v", want, chunks)
+		}
+
+		if chunks := (FixedSizeChunker{Size: 10}).Chunk("short"); len(chunks) != 1 || chunks[0] != "short" {
+			t.Errorf("Expected short text to stay whole, got %v", chunks)
+		}
+
+		// An invalid overlap never skips text
+		chunks = FixedSizeChunker{Size: 4, Overlap: -2}.Chunk("abcdefghij")
+		if want := "abcd,efgh,ij"; strings.Join(chunks, ",") != want {
+			t.Errorf("Expected %v, got %v", want, chunks)
+		}
+	})
+
+	t.Run("Sentence", func(t *testing.T) {
+		text := "One two. Three four five! Six? Seven eight nine ten eleven."
+		chunks := SentenceChunker{MaxSize: 29}.Chunk(text)
+		want := []string{"One two. Three four five!", "Six?", "Seven eight nine ten eleven."}
+		if strings.Join(chunks, "|") != strings.Join(want, "|") {
+			t.Errorf("Expected %q, got %q", want, chunks)
+		}
+		for _, chunk := range chunks {
+			if len(chunk) > 29 {
+				t.Errorf("Chunk %q exceeds max size", chunk)
+			}
+		}
+
+		overlapped := SentenceChunker{MaxSize: 10, Overlap: 1}.Chunk("A b. C d. E f. G h.")
+		if len(overlapped) < 2 || !strings.HasPrefix(overlapped[1], "C d.") {
+			t.Errorf("Expected second chunk to repeat the last sentence, got %q", overlapped)
+		}
+
+		// A negative overlap is ignored rather than panicking
+		if chunks := (SentenceChunker{MaxSize: 20, Overlap: -1}).Chunk(text); strings.Join(chunks, "|") != strings.Join(SentenceChunker{MaxSize: 20}.Chunk(text), "|") {
+			t.Errorf("Expected a negative overlap to act as none, got %q", chunks)
+		}
+	})
+
+	t.Run("Markdown", func(t *testing.T) {
+		text := strings.Join([]string{
+			"Intro paragraph.",
+			"",
+			"# Install",
+			"",
+			"Run the installer.",
+			"",
+			"```",
+			"# not a heading",
+			"```",
+			"",
+			"## Configure",
+			"",
+			"Set the key. Then restart the service. Finally check the logs.",
+		}, "\n")
+
+		chunks := MarkdownChunker{MaxSize: 40}.Chunk(text)
+		if chunks[0] != "Intro paragraph." {
+			t.Errorf("Expected intro chunk first, got %q", chunks[0])
+		}
+		configure := 0
+		for _, chunk := range chunks[1:] {
+			if strings.Contains(chunk, "# not a heading") && !strings.HasPrefix(chunk, "# Install\n\n") {
+				t.Errorf("Expected code fence to stay in the Install section, got %q", chunk)
+			}
+			if strings.Contains(chunk, "restart") || strings.Contains(chunk, "logs") {
+				configure++
+				if !strings.HasPrefix(chunk, "## Configure\n\n") {
+					t.Errorf("Expected Configure chunk to start with its heading, got %q", chunk)
+				}
+			}
+			if len([]rune(chunk)) > 40 {
+				t.Errorf("Chunk %q exceeds max size", chunk)
+			}
+		}
+		if configure < 2 {
+			t.Errorf("Expected the Configure section to be split by sentence, got %q", chunks)
+		}
+	})
+}
+
+func TestInsertChunkedText(t *testing.T) {
+	var mu sync.Mutex
+	inserted := map[string]string{}
+	var removed []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/resource/insert/":
+			var req ResourceInsertRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Type != ResourceTypeText {
+				http.Error(w, `{"error": "unexpected type"}`, http.StatusBadRequest)
+				return
+			}
+			mu.Lock()
+			id := "chunk-" + string(rune('a'+len(inserted)))
+			inserted[id] = req.Resource
+			mu.Unlock()
+			json.NewEncoder(w).Encode(ResourceInsertResponse{Success: true, ResourceID: id, Tokens: 2})
+		case "/v1/resource/remove/":
+			var req ResourceDeleteRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			mu.Lock()
+			removed = append(removed, req.ResourceID)
+			mu.Unlock()
+			json.NewEncoder(w).Encode(ResourceDeleteResponse{Success: true})
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+	ctx := context.Background()
+
+	text := strings.Repeat("word ", 30)
+	group, err := client.RAG.InsertChunkedText(ctx, "transcripts", "talk.txt", text, FixedSizeChunker{Size: 50}, BulkInsertOptions{})
+	if err != nil {
+		t.Fatalf("InsertChunkedText failed: %v", err)
+	}
+	if len(group.ResourceIDs) != 3 || group.Tokens != 6 {
+		t.Fatalf("Expected 3 chunks and 6 tokens, got %+v", group)
+	}
+	for _, id := range group.ResourceIDs {
+		if !strings.HasPrefix(inserted[id], "[source: talk.txt | chunk ") {
+			t.Errorf("Expected chunk %s to be tagged, got %q", id, inserted[id])
+		}
+	}
+
+	for _, chunker := range []Chunker{FixedSizeChunker{Size: 50, Overlap: -1}, FixedSizeChunker{Size: 50, Overlap: 50}, SentenceChunker{MaxSize: 50, Overlap: -1}} {
+		_, err := client.RAG.InsertChunkedText(ctx, "transcripts", "talk.txt", text, chunker, BulkInsertOptions{})
+		if _, ok := err.(ValidationError); !ok {
+			t.Errorf("Expected a ValidationError for %+v, got %v", chunker, err)
+		}
+	}
+
+	if err := client.RAG.RemoveChunkGroup(ctx, group); err != nil {
+		t.Fatalf("RemoveChunkGroup failed: %v", err)
+	}
+	if len(removed) != 3 {
+		t.Errorf("Expected 3 removals, got %v", removed)
+	}
+}
@@ -19,15 +19,35 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // APIClient represents the main client for interacting with the WetroCloud API.
 // It handles authentication, request formatting, and response processing.
 type apiClient struct {
 	baseURL    string
-	apiKey     string
+	uploadURL  string
 	apiVersion string
 	httpClient *http.Client
+
+	// Supplies the API key of every request
+	credentials CredentialProvider
+
+	// How failed requests are retried, by default not at all
+	retry RetryPolicy
+
+	// (optional) Resolves alias names passed as collection IDs
+	aliases AliasStore
+
+	// (optional) Tracks resources inserted with a TTL
+	expiries ExpiryStore
+
+	// (optional) Remembers the content inserted into each collection
+	dedupe DedupeStore
+
+	// Serializes inserts of the same content into the same collection, so
+	// that the dedupe store sees the first before the next looks it up
+	inserting keyedMutex
 }
 
 // Client represents the main entry point for the WetroCloud SDK.
@@ -35,6 +55,8 @@ type apiClient struct {
 type Client struct {
 	RAG   *ragClient
 	Tools *toolsClient
+
+	api *apiClient
 }
 
 // ClientOption represents a function that can modify the APIClient configuration.
@@ -43,10 +65,11 @@ type ClientOption func(*apiClient)
 
 func NewClient(apiKey string, options ...ClientOption) *Client {
 	apiClient := &apiClient{
-		baseURL:    "https://api.wetrocloud.com/",
-		apiKey:     apiKey,
-		apiVersion: "v1",
-		httpClient: &http.Client{},
+		baseURL:     "https://api.wetrocloud.com/",
+		uploadURL:   "https://file-upload-service-python.vercel.app/upload/",
+		apiVersion:  "v1",
+		httpClient:  &http.Client{},
+		credentials: StaticCredentials(apiKey),
 	}
 
 	for _, opt := range options {
@@ -56,11 +79,12 @@ func NewClient(apiKey string, options ...ClientOption) *Client {
 	return &Client{
 		RAG:   newRAGClient(apiClient),
 		Tools: newToolsClient(apiClient),
+		api:   apiClient,
 	}
 }
 
 func newRAGClient(api *apiClient) *ragClient {
-	return &ragClient{client: api}
+	return &ragClient{client: api, ensuring: &ensureGroup{}}
 }
 
 func newToolsClient(api *apiClient) *toolsClient {
@@ -91,33 +115,39 @@ func (c *apiClient) doRequest(ctx context.Context, method, endpoint string, para
 	params["referrer"] = "GO_SDK"
 
 	// Create request
-	var body io.Reader
+	var jsonData []byte
 	if data != nil {
-		jsonData, err := json.Marshal(data)
+		var err error
+		jsonData, err = json.Marshal(data)
 		if err != nil {
 			return err
 		}
-		body = bytes.NewBuffer(jsonData)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, body)
-	if err != nil {
-		return err
-	}
+	newRequest := func() (*http.Request, error) {
+		var body io.Reader
+		if jsonData != nil {
+			body = bytes.NewReader(jsonData)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, body)
+		if err != nil {
+			return nil, err
+		}
 
-	// Add headers
-	req.Header.Set("Authorization", fmt.Sprintf("Token %s", c.apiKey))
-	req.Header.Set("Content-Type", "application/json")
+		// Add headers
+		req.Header.Set("Content-Type", "application/json")
 
-	// Add query parameters
-	q := req.URL.Query()
-	for k, v := range params {
-		q.Add(k, v)
+		// Add query parameters
+		q := req.URL.Query()
+		for k, v := range params {
+			q.Add(k, v)
+		}
+		req.URL.RawQuery = q.Encode()
+		return req, nil
 	}
-	req.URL.RawQuery = q.Encode()
 
 	// Send request
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(ctx, newRequest)
 	if err != nil {
 		return err
 	}
@@ -128,24 +158,7 @@ func (c *apiClient) doRequest(ctx context.Context, method, endpoint string, para
 
 	// Check for errors
 	if resp.StatusCode >= 400 {
-		var errorResp struct {
-			Error   string `json:"error"`
-			Detail  string `json:"detail"`
-			Payload any    `json:"payload,omitempty"`
-		}
-		fmt.Println("kjhflkjdhoj", resp.StatusCode)
-		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err != nil {
-			return &APIError{
-				Message:    "Failed to parse error response",
-				StatusCode: resp.StatusCode,
-			}
-		}
-
-		return &APIError{
-			Message:    parseError(resp),
-			StatusCode: resp.StatusCode,
-			Payload:    errorResp.Payload,
-		}
+		return newAPIError(resp)
 	}
 
 	// Parse response
@@ -162,10 +175,22 @@ func (c *apiClient) doMultipartRequest(ctx context.Context, method, endpoint str
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
 
-	// Add form fields
+	// Add form fields. Readers are sent as files.
 	for k, v := range data {
 		if str, ok := v.(string); ok {
 			writer.WriteField(k, str)
+		} else if reader, ok := v.(io.Reader); ok {
+			filename := k
+			if named, ok := v.(interface{ Name() string }); ok {
+				filename = filepath.Base(named.Name())
+			}
+			part, err := writer.CreateFormFile(k, filename)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(part, reader); err != nil {
+				return err
+			}
 		} else {
 			jsonData, err := json.Marshal(v)
 			if err != nil {
@@ -180,18 +205,19 @@ func (c *apiClient) doMultipartRequest(ctx context.Context, method, endpoint str
 		return err
 	}
 
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, method, url, &buf)
-	if err != nil {
-		return err
-	}
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			return nil, err
+		}
 
-	// Add headers
-	req.Header.Set("Authorization", fmt.Sprintf("Token %s", c.apiKey))
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+		// Add headers
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req, nil
+	}
 
 	// Send request
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(ctx, newRequest)
 	if err != nil {
 		return err
 	}
@@ -199,30 +225,97 @@ func (c *apiClient) doMultipartRequest(ctx context.Context, method, endpoint str
 
 	// Check for errors
 	if resp.StatusCode >= 400 {
-		var errorResp struct {
-			Error   string `json:"error"`
-			Detail  string `json:"detail"`
-			Payload any    `json:"payload"`
+		return newAPIError(resp)
+	}
+
+	// Parse response
+	if response != nil {
+		return json.NewDecoder(resp.Body).Decode(response)
+	}
+	return nil
+}
+
+// send authenticates and sends the request built by newRequest, applying
+// the options of the call and retrying failed attempts as its retry policy
+// allows. If the API rejects the key, the credentials are refreshed and the
+// request is sent again once with the refreshed key.
+func (c *apiClient) send(ctx context.Context, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	o := callOptionsFrom(ctx)
+	policy := c.retryPolicy(o)
+
+	key, err := c.credentials.APIKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get API key: %w", err)
+	}
+
+	start := time.Now()
+	sent := 0
+	refreshed := false
+	attempt := 1
+	for {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
 		}
-		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err != nil {
-			return &APIError{
-				Message:    "Failed to parse error response",
-				StatusCode: resp.StatusCode,
+		for k, values := range o.headers {
+			req.Header[k] = values
+		}
+		if o.idempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", o.idempotencyKey)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Token %s", key))
+
+		resp, err := c.httpClient.Do(req)
+		sent++
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && !refreshed {
+			refreshed = true
+			newKey, refreshErr := c.refreshKey(ctx, key)
+			if refreshErr != nil {
+				// The 401 is kept as the main error, with the reason it
+				// could not be recovered from
+				o.capture(resp, time.Since(start), sent)
+				apiErr := newAPIError(resp)
+				resp.Body.Close()
+				return nil, fmt.Errorf("%w; refresh API key: %w", apiErr, refreshErr)
 			}
+			if newKey != "" {
+				resp.Body.Close()
+				key = newKey
+				continue
+			}
+		}
+
+		if attempt >= policy.MaxAttempts || !policy.retryable(resp, err) {
+			o.capture(resp, time.Since(start), sent)
+			return resp, err
 		}
 
-		return &APIError{
-			Message:    parseError(resp),
-			StatusCode: resp.StatusCode,
-			Payload:    errorResp.Payload,
+		delay := policy.backoff(attempt, resp)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, delay); err != nil {
+			o.capture(resp, time.Since(start), sent)
+			return nil, err
 		}
+		attempt++
 	}
+}
 
-	// Parse response
-	if response != nil {
-		return json.NewDecoder(resp.Body).Decode(response)
+// refreshKey refreshes the credentials after key was rejected, and returns
+// the new key, or "" if it did not change.
+func (c *apiClient) refreshKey(ctx context.Context, key string) (string, error) {
+	if err := c.credentials.Refresh(ctx, key); err != nil {
+		return "", err
 	}
-	return nil
+	refreshed, err := c.credentials.APIKey(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get API key: %w", err)
+	}
+	if refreshed == key {
+		return "", nil
+	}
+	return refreshed, nil
 }
 
 func (c *apiClient) uploadBytes(ctx context.Context, collectionID string, resource any) (string, error) {
@@ -255,8 +348,6 @@ func (c *apiClient) uploadFile(ctx context.Context, collectionID string, filePat
 }
 
 func (c *apiClient) upload(ctx context.Context, reader io.Reader, collectionID, filename string) (string, error) {
-	uploadURL := "https://file-upload-service-python.vercel.app/upload/"
-
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
 
@@ -274,7 +365,7 @@ func (c *apiClient) upload(ctx context.Context, reader io.Reader, collectionID,
 	}
 	writer.Close()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, &buf)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.uploadURL, &buf)
 	if err != nil {
 		return "", err
 	}
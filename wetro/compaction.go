@@ -0,0 +1,112 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultCompactionKeepRecent = 4
+
+	// Used as the threshold when the context window of the model is unknown
+	defaultCompactionThreshold = 4000
+
+	// summaryPrefix marks the message holding a summary of earlier turns.
+	summaryPrefix = "Summary of the earlier conversation: "
+
+	defaultSummaryPrompt = "You summarize conversations between a user and an assistant. " +
+		"Write a concise summary of the conversation below that keeps every fact, " +
+		"decision, name and open question needed to continue it. Reply with the summary only."
+)
+
+// HistoryCompactor shrinks a chat history before it is sent with a message.
+type HistoryCompactor interface {
+	Compact(ctx context.Context, history []Message) ([]Message, error)
+}
+
+// CompactionOptions configures a SummarizingCompactor.
+type CompactionOptions struct {
+
+	// Estimated number of history tokens above which the history is
+	// compacted. Defaults to half the context window of Model, or 4000
+	// tokens for models of unknown size.
+	Threshold int
+
+	// Number of most recent messages kept word for word. Defaults to 4.
+	KeepRecent int
+
+	// (optional) The model used to write the summary
+	Model ChatModel
+
+	// (optional) Instructions for the summarizing model, replacing the default.
+	Prompt string
+}
+
+// SummarizingCompactor compacts a chat history by asking GenerateText to
+// summarize its oldest messages, which are then replaced by a single system
+// message holding the summary. A previous summary is folded into the new one.
+type SummarizingCompactor struct {
+	tools *toolsClient
+	opts  CompactionOptions
+}
+
+// NewHistoryCompactor returns a compactor that summarizes chat histories
+// with GenerateText once they grow past opts.Threshold estimated tokens.
+func (c *toolsClient) NewHistoryCompactor(opts CompactionOptions) *SummarizingCompactor {
+	if opts.Threshold <= 0 {
+		opts.Threshold = opts.Model.ContextWindow() / 2
+		if opts.Threshold == 0 {
+			opts.Threshold = defaultCompactionThreshold
+		}
+	}
+	if opts.KeepRecent <= 0 {
+		opts.KeepRecent = defaultCompactionKeepRecent
+	}
+	if opts.Prompt == "" {
+		opts.Prompt = defaultSummaryPrompt
+	}
+	return &SummarizingCompactor{tools: c, opts: opts}
+}
+
+// Compact implements HistoryCompactor. Histories below the threshold, or
+// with nothing older than the messages to keep, are returned unchanged.
+func (s *SummarizingCompactor) Compact(ctx context.Context, history []Message) ([]Message, error) {
+	tokens := EstimateHistoryTokens(s.opts.Model, history)
+	if tokens <= s.opts.Threshold || len(history) <= s.opts.KeepRecent+1 {
+		return history, nil
+	}
+
+	split := len(history) - s.opts.KeepRecent
+	old, recent := history[:split], history[split:]
+
+	var transcript strings.Builder
+	for _, m := range old {
+		content := m.Content()
+		if m.Role() == RoleSystem && strings.HasPrefix(content, summaryPrefix) {
+			fmt.Fprintf(&transcript, "(earlier summary) %s\n\n", strings.TrimPrefix(content, summaryPrefix))
+			continue
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n\n", m.Role(), content)
+	}
+
+	response, err := s.tools.GenerateText(ctx, TextGenerationRequest{
+		Messages: []MessageObject{
+			{Role: RoleSystem, Content: s.opts.Prompt},
+			{Role: RoleUser, Content: transcript.String()},
+		},
+		Model: s.opts.Model,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("summarize chat history: %w", err)
+	}
+
+	summary := strings.TrimSpace(responseText(response.Response))
+	compacted := make([]Message, 0, len(recent)+1)
+	compacted = append(compacted, NewMessage(RoleSystem, summaryPrefix+summary))
+	return append(compacted, copyHistory(recent)...), nil
+}
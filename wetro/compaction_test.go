@@ -0,0 +1,120 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSummarizingCompactor(t *testing.T) {
+	var summarized []string
+	var lastChat ChatRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/text-generation/":
+			var req TextGenerationRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			transcript := req.Messages[len(req.Messages)-1].Content
+			summarized = append(summarized, transcript)
+			json.NewEncoder(w).Encode(StandardResponse{
+				Success:  true,
+				Response: "the user said hello",
+			})
+		case "/v1/collection/chat/":
+			json.NewDecoder(r.Body).Decode(&lastChat)
+			json.NewEncoder(w).Encode(StandardResponse{
+				Success:  true,
+				Response: map[string]string{"response": strings.Repeat("answer ", 10)},
+			})
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+	ctx := context.Background()
+	compactor := client.Tools.NewHistoryCompactor(CompactionOptions{Threshold: 40, KeepRecent: 2})
+
+	t.Run("BelowThreshold", func(t *testing.T) {
+		history := []Message{NewMessage(RoleUser, "hi"), NewMessage(RoleAssistant, "hello")}
+		compacted, err := compactor.Compact(ctx, history)
+		if err != nil {
+			t.Fatalf("Compact failed: %v", err)
+		}
+		if len(compacted) != 2 || len(summarized) != 0 {
+			t.Errorf("Expected a short history to be left alone, got %v", compacted)
+		}
+	})
+
+	t.Run("Summarize", func(t *testing.T) {
+		history := []Message{
+			NewMessage(RoleUser, strings.Repeat("hello ", 20)),
+			NewMessage(RoleAssistant, strings.Repeat("hi ", 20)),
+			NewMessage(RoleUser, "question"),
+			NewMessage(RoleAssistant, "answer"),
+		}
+		compacted, err := compactor.Compact(ctx, history)
+		if err != nil {
+			t.Fatalf("Compact failed: %v", err)
+		}
+		if len(compacted) != 3 {
+			t.Fatalf("Expected a summary and 2 recent messages, got %v", compacted)
+		}
+		if compacted[0].Role() != RoleSystem || !strings.HasSuffix(compacted[0].Content(), "the user said hello") {
+			t.Errorf("Unexpected summary message %v", compacted[0])
+		}
+		if compacted[1].Content() != "question" {
+			t.Errorf("Expected recent messages to be kept, got %v", compacted[1:])
+		}
+		if len(summarized) != 1 || !strings.Contains(summarized[0], "user: hello") || strings.Contains(summarized[0], "question") {
+			t.Errorf("Expected only the oldest messages to be summarized, got %q", summarized)
+		}
+	})
+
+	t.Run("ChatSession", func(t *testing.T) {
+		summarized = nil
+		session := client.RAG.NewChatSession("docs", ChatSessionOptions{Compactor: compactor})
+		for _, msg := range []string{"one", "two", "three", "four"} {
+			if _, err := session.Send(ctx, msg); err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+		}
+		if len(summarized) == 0 {
+			t.Fatal("Expected the session history to be summarized")
+		}
+		if lastChat.ChatHistory[0].Role() != RoleSystem || len(lastChat.ChatHistory) != 3 {
+			t.Errorf("Expected the compacted history to be sent, got %v", lastChat.ChatHistory)
+		}
+		if len(summarized) > 1 && !strings.Contains(summarized[len(summarized)-1], "(earlier summary)") {
+			t.Errorf("Expected the previous summary to be folded in, got %q", summarized)
+		}
+	})
+}
+
+func TestSummarizingCompactorDefaultThreshold(t *testing.T) {
+	client := NewClient("test-api-key")
+	for model, want := range map[ChatModel]int{GPT4: 4096, Claude35Sonnet20241022: 100000, "": 4000} {
+		compactor := client.Tools.NewHistoryCompactor(CompactionOptions{Model: model})
+		if compactor.opts.Threshold != want {
+			t.Errorf("Expected a threshold of %d for %q, got %d", want, model, compactor.opts.Threshold)
+		}
+	}
+
+	// A short history is left alone, without calling the API
+	history := []Message{
+		NewMessage(RoleUser, "one"), NewMessage(RoleAssistant, "two"),
+		NewMessage(RoleUser, "three"), NewMessage(RoleAssistant, "four"),
+		NewMessage(RoleUser, "five"), NewMessage(RoleAssistant, "six"),
+	}
+	compacted, err := client.Tools.NewHistoryCompactor(CompactionOptions{}).Compact(context.Background(), history)
+	if err != nil || len(compacted) != len(history) {
+		t.Errorf("Expected the history to be left alone, got %v, %v", compacted, err)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialProvider supplies the API key of every request, so that keys can
+// be rotated without restarting the process.
+type CredentialProvider interface {
+
+	// APIKey returns the key to authenticate the next request with.
+	APIKey(ctx context.Context) (string, error)
+
+	// Refresh is called when the API rejects a key with 401 Unauthorized.
+	// The request is retried once if APIKey then returns a different key.
+	Refresh(ctx context.Context, rejected string) error
+}
+
+// WithCredentialProvider sets the provider of the API key, in place of the
+// key passed to NewClient.
+func WithCredentialProvider(provider CredentialProvider) ClientOption {
+	return func(c *apiClient) {
+		c.credentials = provider
+	}
+}
+
+// StaticCredentials is a CredentialProvider that always returns the same key.
+type StaticCredentials string
+
+// APIKey implements CredentialProvider.
+func (s StaticCredentials) APIKey(ctx context.Context) (string, error) {
+	return string(s), nil
+}
+
+// Refresh implements CredentialProvider. A static key cannot be refreshed.
+func (s StaticCredentials) Refresh(ctx context.Context, rejected string) error {
+	return nil
+}
+
+// EnvCredentials is a CredentialProvider that reads the key from the named
+// environment variable on every request.
+type EnvCredentials string
+
+// APIKey implements CredentialProvider.
+func (e EnvCredentials) APIKey(ctx context.Context) (string, error) {
+	key, ok := os.LookupEnv(string(e))
+	if !ok || key == "" {
+		return "", fmt.Errorf("environment variable %s is not set", string(e))
+	}
+	return key, nil
+}
+
+// Refresh implements CredentialProvider. The variable is read again on the
+// retry.
+func (e EnvCredentials) Refresh(ctx context.Context, rejected string) error {
+	return nil
+}
+
+// CredentialFunc is a CredentialProvider calling a function for the key of
+// every request, for keys held in a secret manager. The function should
+// cache the key if fetching it is expensive.
+type CredentialFunc func(ctx context.Context) (string, error)
+
+// APIKey implements CredentialProvider.
+func (f CredentialFunc) APIKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// Refresh implements CredentialProvider. The function is called again on the
+// retry.
+func (f CredentialFunc) Refresh(ctx context.Context, rejected string) error {
+	return nil
+}
+
+// FileCredentials is a CredentialProvider reading the key from a file, such
+// as a mounted secret. The file is reloaded whenever it changes, and when
+// the API rejects the key. Surrounding whitespace is ignored.
+// It is safe for concurrent use.
+type FileCredentials struct {
+	path string
+
+	mu      sync.Mutex
+	key     string
+	modTime time.Time
+	size    int64
+}
+
+// NewFileCredentials returns a provider for the key in the file at path.
+// The file is read once to check that it holds a key.
+func NewFileCredentials(path string) (*FileCredentials, error) {
+	f := &FileCredentials{path: path}
+	if _, err := f.APIKey(context.Background()); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// APIKey implements CredentialProvider.
+func (f *FileCredentials) APIKey(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", err
+	}
+	if f.key != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.key, nil
+	}
+	return f.load(info)
+}
+
+// Refresh implements CredentialProvider. The file is read again even if it
+// looks unchanged, unless the key was already reloaded since it was rejected.
+func (f *FileCredentials) Refresh(ctx context.Context, rejected string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.key != rejected {
+		return nil
+	}
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+	_, err = f.load(info)
+	return err
+}
+
+func (f *FileCredentials) load(info os.FileInfo) (string, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", err
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("credential file %s is empty", f.path)
+	}
+	f.key, f.modTime, f.size = key, info.ModTime(), info.Size()
+	return key, nil
+}
@@ -0,0 +1,156 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func newAuthServer(t *testing.T, validKey *atomic.Value, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Authorization") != "Token "+validKey.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"detail": "Invalid token."})
+			return
+		}
+		json.NewEncoder(w).Encode(GetCollectionResponse{Success: true, Found: true, CollectionID: "docs"})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCredentialProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("FileCredentialsRotation", func(t *testing.T) {
+		var validKey atomic.Value
+		var requests atomic.Int32
+		validKey.Store("key-1")
+		server := newAuthServer(t, &validKey, &requests)
+
+		path := filepath.Join(t.TempDir(), "api-key")
+		os.WriteFile(path, []byte("key-1\n"), 0o600)
+		credentials, err := NewFileCredentials(path)
+		if err != nil {
+			t.Fatalf("NewFileCredentials failed: %v", err)
+		}
+		client := NewClient("", WithCredentialProvider(credentials), func(c *apiClient) {
+			c.baseURL = server.URL + "/"
+		})
+
+		if _, err := client.RAG.GetCollection(ctx, "docs"); err != nil {
+			t.Fatalf("GetCollection failed: %v", err)
+		}
+
+		// Rotate the key without a visible change to the file's metadata
+		validKey.Store("key-2")
+		info, _ := os.Stat(path)
+		os.WriteFile(path, []byte("key-2\n"), 0o600)
+		os.Chtimes(path, info.ModTime(), info.ModTime())
+
+		requests.Store(0)
+		if _, err := client.RAG.GetCollection(ctx, "docs"); err != nil {
+			t.Fatalf("Expected the request to be retried with the refreshed key, got %v", err)
+		}
+		if requests.Load() != 2 {
+			t.Errorf("Expected 2 requests, got %d", requests.Load())
+		}
+	})
+
+	t.Run("NoRetryWithSameKey", func(t *testing.T) {
+		var validKey atomic.Value
+		var requests atomic.Int32
+		validKey.Store("other")
+		server := newAuthServer(t, &validKey, &requests)
+
+		client := NewClient("test-api-key", func(c *apiClient) {
+			c.baseURL = server.URL + "/"
+		})
+		_, err := client.RAG.GetCollection(ctx, "docs")
+		apiErr, ok := err.(*APIError)
+		if !ok || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "Invalid token." {
+			t.Errorf("Expected a 401 APIError, got %v", err)
+		}
+		if requests.Load() != 1 {
+			t.Errorf("Expected 1 request, got %d", requests.Load())
+		}
+	})
+
+	t.Run("RefreshError", func(t *testing.T) {
+		var validKey atomic.Value
+		var requests atomic.Int32
+		validKey.Store("other")
+		server := newAuthServer(t, &validKey, &requests)
+
+		errVault := errors.New("vault unreachable")
+		client := NewClient("", WithCredentialProvider(failingRefresh{errVault}), func(c *apiClient) {
+			c.baseURL = server.URL + "/"
+		})
+		_, err := client.RAG.GetCollection(ctx, "docs")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected a 401 APIError, got %v", err)
+		}
+		if !errors.Is(err, errVault) {
+			t.Errorf("Expected the refresh error to be wrapped, got %v", err)
+		}
+		if requests.Load() != 1 {
+			t.Errorf("Expected 1 request, got %d", requests.Load())
+		}
+	})
+
+	t.Run("CredentialFuncPerRequest", func(t *testing.T) {
+		var validKey atomic.Value
+		var requests, calls atomic.Int32
+		validKey.Store("key-2")
+		server := newAuthServer(t, &validKey, &requests)
+
+		credentials := CredentialFunc(func(ctx context.Context) (string, error) {
+			if calls.Add(1) == 1 {
+				return "key-1", nil
+			}
+			return "key-2", nil
+		})
+		client := NewClient("", WithCredentialProvider(credentials), func(c *apiClient) {
+			c.baseURL = server.URL + "/"
+		})
+		if _, err := client.RAG.GetCollection(ctx, "docs"); err != nil {
+			t.Fatalf("Expected the request to be retried with the new key, got %v", err)
+		}
+		if requests.Load() != 2 {
+			t.Errorf("Expected 2 requests, got %d", requests.Load())
+		}
+	})
+
+	t.Run("EnvCredentials", func(t *testing.T) {
+		t.Setenv("WETRO_TEST_API_KEY", "env-key")
+		key, err := EnvCredentials("WETRO_TEST_API_KEY").APIKey(ctx)
+		if err != nil || key != "env-key" {
+			t.Errorf("Expected env-key, got %q, %v", key, err)
+		}
+		if _, err := EnvCredentials("WETRO_TEST_UNSET_KEY").APIKey(ctx); err == nil {
+			t.Error("Expected an error for an unset variable")
+		}
+	})
+}
+
+// failingRefresh is a CredentialProvider whose key cannot be refreshed.
+type failingRefresh struct {
+	err error
+}
+
+func (f failingRefresh) APIKey(ctx context.Context) (string, error) {
+	return "test-api-key", nil
+}
+
+func (f failingRefresh) Refresh(ctx context.Context, rejected string) error {
+	return f.err
+}
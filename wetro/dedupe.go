@@ -0,0 +1,295 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DedupeStore remembers the content inserted into each collection, keyed by
+// a hash of the content, so that InsertResource does not insert the same
+// content twice. Collection IDs are full IDs, including any namespace.
+type DedupeStore interface {
+
+	// Lookup returns the resource a content hash was inserted as, if any.
+	Lookup(ctx context.Context, collectionID, hash string) (resourceID string, found bool, err error)
+
+	// Record remembers that content was inserted as a resource.
+	Record(ctx context.Context, collectionID, hash, resourceID string) error
+
+	// Forget drops the content of a removed resource.
+	Forget(ctx context.Context, collectionID, resourceID string) error
+
+	// ForgetCollection drops the content of a deleted collection.
+	ForgetCollection(ctx context.Context, collectionID string) error
+}
+
+// WithDedupeStore makes InsertResource skip content that was already
+// inserted into the same collection, as recorded in store. RemoveResource
+// and DeleteCollection keep the store up to date.
+//
+// Concurrent inserts of the same content through the client are run one
+// after the other, so only the first reaches the API. To be hashed,
+// io.Reader resources are read into memory whole before they are uploaded.
+func WithDedupeStore(store DedupeStore) ClientOption {
+	return func(c *apiClient) {
+		c.dedupe = store
+	}
+}
+
+// keyedMutex is a set of mutexes by key, created as they are needed and
+// dropped once no one holds or waits for them. The zero value is ready
+// to use.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock locks the mutex of key and returns the function unlocking it.
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyedLock)
+	}
+	l := k.locks[key]
+	if l == nil {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		k.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
+
+// dedupeTable maps collection IDs to content hashes to resource IDs.
+type dedupeTable map[string]map[string]string
+
+func (t dedupeTable) lookup(collectionID, hash string) (string, bool) {
+	resourceID, ok := t[collectionID][hash]
+	return resourceID, ok
+}
+
+func (t dedupeTable) record(collectionID, hash, resourceID string) {
+	if t[collectionID] == nil {
+		t[collectionID] = make(map[string]string)
+	}
+	t[collectionID][hash] = resourceID
+}
+
+// forget reports whether the table changed.
+func (t dedupeTable) forget(collectionID, resourceID string) bool {
+	changed := false
+	for hash, id := range t[collectionID] {
+		if id == resourceID {
+			delete(t[collectionID], hash)
+			changed = true
+		}
+	}
+	if changed && len(t[collectionID]) == 0 {
+		delete(t, collectionID)
+	}
+	return changed
+}
+
+// MemoryDedupeStore is a DedupeStore that keeps hashes in memory.
+// It is safe for concurrent use.
+type MemoryDedupeStore struct {
+	mu    sync.Mutex
+	table dedupeTable
+}
+
+// NewMemoryDedupeStore returns an empty in-memory dedupe store.
+func NewMemoryDedupeStore() *MemoryDedupeStore {
+	return &MemoryDedupeStore{table: make(dedupeTable)}
+}
+
+// Lookup implements DedupeStore.
+func (s *MemoryDedupeStore) Lookup(ctx context.Context, collectionID, hash string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resourceID, ok := s.table.lookup(collectionID, hash)
+	return resourceID, ok, nil
+}
+
+// Record implements DedupeStore.
+func (s *MemoryDedupeStore) Record(ctx context.Context, collectionID, hash, resourceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.table.record(collectionID, hash, resourceID)
+	return nil
+}
+
+// Forget implements DedupeStore.
+func (s *MemoryDedupeStore) Forget(ctx context.Context, collectionID, resourceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.table.forget(collectionID, resourceID)
+	return nil
+}
+
+// ForgetCollection implements DedupeStore.
+func (s *MemoryDedupeStore) ForgetCollection(ctx context.Context, collectionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.table, collectionID)
+	return nil
+}
+
+// FileDedupeStore is a DedupeStore that keeps every hash in a single JSON
+// file. Like FileAliasStore, changes are renamed into place and made under
+// a lock on a ".lock" file next to it, so several processes can share it.
+type FileDedupeStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileDedupeStore returns a dedupe store backed by the file at path. The
+// file is created on the first change.
+func NewFileDedupeStore(path string) (*FileDedupeStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return &FileDedupeStore{path: path}, nil
+}
+
+func (s *FileDedupeStore) load() (dedupeTable, error) {
+	table := make(dedupeTable)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return table, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("invalid dedupe file %s: %w", s.path, err)
+	}
+	return table, nil
+}
+
+// withLock runs fn under the lock of the dedupe file, shared by every
+// process using it.
+func (s *FileDedupeStore) withLock(fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return withFileLock(s.path+".lock", fn)
+}
+
+func (s *FileDedupeStore) save(table dedupeTable) error {
+	data, err := json.MarshalIndent(table, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data, 0o644)
+}
+
+// Lookup implements DedupeStore.
+func (s *FileDedupeStore) Lookup(ctx context.Context, collectionID, hash string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	table, err := s.load()
+	if err != nil {
+		return "", false, err
+	}
+	resourceID, ok := table.lookup(collectionID, hash)
+	return resourceID, ok, nil
+}
+
+// Record implements DedupeStore.
+func (s *FileDedupeStore) Record(ctx context.Context, collectionID, hash, resourceID string) error {
+	return s.withLock(func() error {
+		table, err := s.load()
+		if err != nil {
+			return err
+		}
+		table.record(collectionID, hash, resourceID)
+		return s.save(table)
+	})
+}
+
+// Forget implements DedupeStore.
+func (s *FileDedupeStore) Forget(ctx context.Context, collectionID, resourceID string) error {
+	return s.withLock(func() error {
+		table, err := s.load()
+		if err != nil {
+			return err
+		}
+		if !table.forget(collectionID, resourceID) {
+			return nil
+		}
+		return s.save(table)
+	})
+}
+
+// ForgetCollection implements DedupeStore.
+func (s *FileDedupeStore) ForgetCollection(ctx context.Context, collectionID string) error {
+	return s.withLock(func() error {
+		table, err := s.load()
+		if err != nil {
+			return err
+		}
+		if _, ok := table[collectionID]; !ok {
+			return nil
+		}
+		delete(table, collectionID)
+		return s.save(table)
+	})
+}
+
+// contentHash hashes the content of a resource along with its type. Local
+// files are hashed by content; readers are read into memory, and returned
+// as a new reader to be inserted in their place.
+func contentHash(resource any, resourceType ResourceType) (string, any, error) {
+	h := sha256.New()
+	io.WriteString(h, string(resourceType)+"\x00")
+
+	switch r := resource.(type) {
+	case io.Reader:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return "", nil, err
+		}
+		h.Write(data)
+		resource = bytes.NewReader(data)
+	case string:
+		if path, ok := uploadPath(r, resourceType); ok {
+			fileHash, err := hashFile(path)
+			if err != nil {
+				return "", nil, err
+			}
+			io.WriteString(h, fileHash)
+		} else {
+			io.WriteString(h, r)
+		}
+	default:
+		fmt.Fprintf(h, "%v", resource)
+	}
+	return hex.EncodeToString(h.Sum(nil)), resource, nil
+}
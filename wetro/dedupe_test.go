@@ -0,0 +1,250 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInsertResourceIdempotency(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		json.NewEncoder(w).Encode(ResourceInsertResponse{Success: true, ResourceID: "r1"})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}), func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+	ctx := context.Background()
+
+	if _, err := client.RAG.InsertResource(ctx, "docs", "hello", ResourceTypeText); err != nil {
+		t.Fatalf("InsertResource failed: %v", err)
+	}
+	client.RAG.InsertResource(ctx, "docs", "hello", ResourceTypeText)
+	client.RAG.InsertResource(ctx, "docs", "hello", ResourceTypeText, WithIdempotencyKey("mine"))
+
+	if len(keys) != 4 {
+		t.Fatalf("Expected 4 requests, got %d", len(keys))
+	}
+	if keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("Expected the key to be reused across retries, got %q and %q", keys[0], keys[1])
+	}
+	if keys[2] == "" || keys[2] == keys[0] {
+		t.Errorf("Expected a new key per insert, got %q", keys[2])
+	}
+	if keys[3] != "mine" {
+		t.Errorf("Expected the given key to be used, got %q", keys[3])
+	}
+}
+
+func TestDedupeStore(t *testing.T) {
+	var mu sync.Mutex
+	inserts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/v1/resource/insert/":
+			inserts++
+			json.NewEncoder(w).Encode(ResourceInsertResponse{Success: true, ResourceID: "r" + strconv.Itoa(inserts)})
+		case "/v1/resource/remove/":
+			json.NewEncoder(w).Encode(ResourceDeleteResponse{Success: true})
+		case "/v1/collection/delete/":
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"detail": "Not found."})
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "dedupe.json")
+
+	newClient := func() *Client {
+		store, err := NewFileDedupeStore(path)
+		if err != nil {
+			t.Fatalf("NewFileDedupeStore failed: %v", err)
+		}
+		return NewClient("test-api-key", WithDedupeStore(store), func(c *apiClient) {
+			c.baseURL = server.URL + "/"
+		})
+	}
+	client := newClient()
+
+	first, err := client.RAG.InsertResource(ctx, "docs", "hello", ResourceTypeText)
+	if err != nil || first.Duplicate {
+		t.Fatalf("Expected the first insert to reach the API, got %+v, %v", first, err)
+	}
+
+	// The table survives a restart
+	client = newClient()
+	second, err := client.RAG.InsertResource(ctx, "docs", "hello", ResourceTypeText)
+	if err != nil || !second.Duplicate || second.ResourceID != first.ResourceID {
+		t.Errorf("Expected a duplicate of %s, got %+v, %v", first.ResourceID, second, err)
+	}
+
+	client.RAG.InsertResource(ctx, "other", "hello", ResourceTypeText)
+	client.RAG.InsertResource(ctx, "docs", "hello", ResourceTypeWeb)
+	if inserts != 3 {
+		t.Errorf("Expected other collections and types to be inserted, got %d inserts", inserts)
+	}
+
+	client.RAG.RemoveResource(ctx, ResourceDeleteRequest{CollectionID: "docs", ResourceID: first.ResourceID})
+	if third, _ := client.RAG.InsertResource(ctx, "docs", "hello", ResourceTypeText); third.Duplicate {
+		t.Error("Expected removed content to be inserted again")
+	}
+
+	// The collection is gone already; its content is dropped all the same
+	client.RAG.DeleteCollection(ctx, "other")
+	if fourth, _ := client.RAG.InsertResource(ctx, "other", "hello", ResourceTypeText); fourth.Duplicate {
+		t.Error("Expected the content of a deleted collection to be dropped")
+	}
+}
+
+func TestBulkIdempotencyKeys(t *testing.T) {
+	var mu sync.Mutex
+	keys := map[string][]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ResourceInsertRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		mu.Lock()
+		defer mu.Unlock()
+		source := req.Resource
+		keys[source] = append(keys[source], r.Header.Get("Idempotency-Key"))
+		if len(keys[source]) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]string{"detail": "Slow down."})
+			return
+		}
+		json.NewEncoder(w).Encode(ResourceInsertResponse{Success: true, ResourceID: source})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+	resources := []Resource{{Source: "a", Type: ResourceTypeText}, {Source: "b", Type: ResourceTypeText}}
+	opts := BulkInsertOptions{RetryBackoff: time.Millisecond}
+
+	check := func(t *testing.T, prefix string) {
+		t.Helper()
+		seen := map[string]bool{}
+		for source, got := range keys {
+			if len(got) != 2 || got[0] == "" || got[0] != got[1] {
+				t.Errorf("Expected %s to reuse its key across retries, got %v", source, got)
+			}
+			if seen[got[0]] {
+				t.Errorf("Expected a distinct key per resource, got %v", keys)
+			}
+			seen[got[0]] = true
+			if prefix != "" && got[0] != prefix+"-0" && got[0] != prefix+"-1" {
+				t.Errorf("Expected a key derived from %s, got %q", prefix, got[0])
+			}
+		}
+	}
+
+	t.Run("Generated", func(t *testing.T) {
+		keys = map[string][]string{}
+		if _, err := client.RAG.InsertResources(context.Background(), "docs", resources, opts); err != nil {
+			t.Fatalf("InsertResources failed: %v", err)
+		}
+		check(t, "")
+	})
+
+	t.Run("Derived", func(t *testing.T) {
+		keys = map[string][]string{}
+		if _, err := client.RAG.InsertResources(context.Background(), "docs", resources, opts, WithIdempotencyKey("batch")); err != nil {
+			t.Fatalf("InsertResources failed: %v", err)
+		}
+		check(t, "batch")
+	})
+}
+
+func TestDedupeConcurrentInserts(t *testing.T) {
+	var mu sync.Mutex
+	inserts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inserts++
+		id := "r" + strconv.Itoa(inserts)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		json.NewEncoder(w).Encode(ResourceInsertResponse{Success: true, ResourceID: id})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", WithDedupeStore(NewMemoryDedupeStore()), func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+
+	var wg sync.WaitGroup
+	responses := make([]ResourceInsertResponse, 8)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], _ = client.RAG.InsertResource(context.Background(), "docs", "hello", ResourceTypeText)
+		}(i)
+	}
+	wg.Wait()
+
+	if inserts != 1 {
+		t.Errorf("Expected the content to be inserted once, got %d inserts", inserts)
+	}
+	for _, resp := range responses {
+		if resp.ResourceID != "r1" {
+			t.Errorf("Expected every insert to return r1, got %+v", resp)
+		}
+	}
+	if len(client.RAG.client.inserting.locks) != 0 {
+		t.Error("Expected the insert locks to be dropped")
+	}
+}
+
+func TestFileDedupeStoreShared(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "dedupe.json")
+
+	// Stores of their own stand in for separate processes
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			store, _ := NewFileDedupeStore(path)
+			store.Record(ctx, "docs", "hash-"+strconv.Itoa(i), "r"+strconv.Itoa(i))
+		}(i)
+	}
+	wg.Wait()
+
+	store, err := NewFileDedupeStore(path)
+	if err != nil {
+		t.Fatalf("NewFileDedupeStore failed: %v", err)
+	}
+	for i := 0; i < 8; i++ {
+		if id, found, _ := store.Lookup(ctx, "docs", "hash-"+strconv.Itoa(i)); !found || id != "r"+strconv.Itoa(i) {
+			t.Errorf("Expected hash-%d to be recorded, got %q, %v", i, id, found)
+		}
+	}
+
+	other, _ := NewFileDedupeStore(path)
+	other.Forget(ctx, "docs", "r0")
+	if _, found, _ := store.Lookup(ctx, "docs", "hash-0"); found {
+		t.Error("Expected a forget made through another store to be seen")
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// ErrAmbiguousResource is returned by DetectResourceType when a source could
+// reasonably be of more than one type. Pass an explicit ResourceType instead.
+var ErrAmbiguousResource = errors.New("ambiguous resource")
+
+// Detection is the outcome of DetectResourceType.
+type Detection struct {
+	Type ResourceType
+
+	// The MIME type sniffed from the content of local files, empty otherwise
+	MIMEType string
+}
+
+// DetectResourceType classifies a resource source. Strings are checked in
+// this order:
+//
+//   - YouTube video URLs (youtube.com and youtu.be) are ResourceTypeYouTube
+//   - other http and https URLs are ResourceTypeWeb
+//   - paths to existing files are ResourceTypeFile
+//   - JSON objects and arrays are ResourceTypeJSON
+//   - anything else is ResourceTypeText
+//
+// Strings that look like a URL or a file path but are neither a valid
+// http(s) URL nor an existing file are rejected with ErrAmbiguousResource.
+// An io.Reader is always a ResourceTypeFile, since it is uploaded.
+func DetectResourceType(source any) (Detection, error) {
+	switch s := source.(type) {
+	case string:
+		return detectString(s)
+	case []byte:
+		return detectString(string(s))
+	case io.Reader:
+		return Detection{Type: ResourceTypeFile}, nil
+	default:
+		return Detection{}, fmt.Errorf("cannot detect the resource type of %T", source)
+	}
+}
+
+func detectString(s string) (Detection, error) {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return Detection{}, errors.New("resource is empty")
+	}
+
+	if scheme, _, ok := strings.Cut(trimmed, "://"); ok && !strings.ContainsAny(scheme, " \t\n") {
+		u, err := url.Parse(trimmed)
+		if err != nil || u.Host == "" {
+			return Detection{}, fmt.Errorf("%w: %q is not a valid URL", ErrAmbiguousResource, trimmed)
+		}
+		switch strings.ToLower(u.Scheme) {
+		case "http", "https":
+		default:
+			return Detection{}, fmt.Errorf("%w: unsupported URL scheme %q", ErrAmbiguousResource, u.Scheme)
+		}
+		if isYouTubeURL(u) {
+			return Detection{Type: ResourceTypeYouTube}, nil
+		}
+		return Detection{Type: ResourceTypeWeb}, nil
+	}
+
+	if !strings.ContainsAny(trimmed, "\n\r") {
+		if info, err := os.Stat(trimmed); err == nil {
+			if !info.Mode().IsRegular() {
+				return Detection{}, fmt.Errorf("%s is not a regular file", trimmed)
+			}
+			mimeType, err := sniffFile(trimmed)
+			if err != nil {
+				return Detection{}, err
+			}
+			return Detection{Type: ResourceTypeFile, MIMEType: mimeType}, nil
+		}
+	}
+
+	if (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid([]byte(trimmed)) {
+		return Detection{Type: ResourceTypeJSON}, nil
+	}
+
+	if looksLikeLocation(trimmed) {
+		return Detection{}, fmt.Errorf("%w: %q looks like a URL or file path but is neither a http(s) URL nor an existing file", ErrAmbiguousResource, trimmed)
+	}
+	return Detection{Type: ResourceTypeText}, nil
+}
+
+// isYouTubeURL reports whether u points at a YouTube video.
+func isYouTubeURL(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	for _, prefix := range []string{"www.", "m.", "music."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+
+	switch host {
+	case "youtu.be":
+		return strings.Trim(u.Path, "/") != ""
+	case "youtube.com", "youtube-nocookie.com":
+		if u.Path == "/watch" {
+			return u.Query().Get("v") != ""
+		}
+		for _, prefix := range []string{"/shorts/", "/embed/", "/live/", "/v/"} {
+			if strings.HasPrefix(u.Path, prefix) && len(u.Path) > len(prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// looksLikeLocation reports whether a single word looks like a URL without
+// a scheme or a path to a file.
+func looksLikeLocation(s string) bool {
+	if strings.ContainsAny(s, " \t\n\r") {
+		return false
+	}
+	if strings.HasPrefix(strings.ToLower(s), "www.") {
+		return true
+	}
+	if strings.ContainsAny(s, `/\`) {
+		return true
+	}
+	ext := filepath.Ext(s)
+	if len(ext) < 2 || len(ext) > 6 || ext == s {
+		return false
+	}
+	for _, r := range ext[1:] {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return strings.IndexFunc(ext, unicode.IsLetter) >= 0
+}
+
+// sniffFile detects the MIME type of a file from its first 512 bytes.
+func sniffFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
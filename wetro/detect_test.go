@@ -0,0 +1,103 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectResourceType(t *testing.T) {
+	dir := t.TempDir()
+	pdf := filepath.Join(dir, "report.pdf")
+	if err := os.WriteFile(pdf, []byte("%PDF-1.7\n..."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		source any
+		want   ResourceType
+		mime   string
+	}{
+		{"YouTubeWatch", "https://www.youtube.com/watch?v=dQw4w9WgXcQ", ResourceTypeYouTube, ""},
+		{"YouTubeShort", "https://youtu.be/dQw4w9WgXcQ", ResourceTypeYouTube, ""},
+		{"YouTubeShorts", "https://m.youtube.com/shorts/abc123", ResourceTypeYouTube, ""},
+		{"YouTubeChannel", "https://www.youtube.com/@wetrocloud", ResourceTypeWeb, ""},
+		{"Web", "https://docs.wetrocloud.com/introduction", ResourceTypeWeb, ""},
+		{"File", pdf, ResourceTypeFile, "application/pdf"},
+		{"JSONObject", `{"name": "lamp"}`, ResourceTypeJSON, ""},
+		{"JSONArray", []byte(`[1, 2, 3]`), ResourceTypeJSON, ""},
+		{"Text", "The quick brown fox jumps over the lazy dog.", ResourceTypeText, ""},
+		{"Number", "3.14", ResourceTypeText, ""},
+		{"BrokenJSON", `{"name": `, ResourceTypeText, ""},
+		{"Reader", strings.NewReader("content"), ResourceTypeFile, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectResourceType(tt.source)
+			if err != nil {
+				t.Fatalf("DetectResourceType failed: %v", err)
+			}
+			if got.Type != tt.want || got.MIMEType != tt.mime {
+				t.Errorf("Expected %s (%q), got %s (%q)", tt.want, tt.mime, got.Type, got.MIMEType)
+			}
+		})
+	}
+
+	ambiguous := []string{
+		"ftp://example.com/file.txt",
+		"www.example.com",
+		filepath.Join(dir, "missing.pdf"),
+		"notes.md",
+	}
+	for _, source := range ambiguous {
+		if _, err := DetectResourceType(source); !errors.Is(err, ErrAmbiguousResource) {
+			t.Errorf("Expected %q to be ambiguous, got %v", source, err)
+		}
+	}
+
+	if _, err := DetectResourceType("   "); err == nil {
+		t.Error("Expected empty resource to be rejected")
+	}
+	if _, err := DetectResourceType(42); err == nil {
+		t.Error("Expected unsupported source type to be rejected")
+	}
+}
+
+func TestInsertResourceAutoDetect(t *testing.T) {
+	var got ResourceInsertRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(ResourceInsertResponse{Success: true, ResourceID: "auto"})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+	ctx := context.Background()
+
+	if _, err := client.RAG.InsertResource(ctx, "docs", "https://youtu.be/dQw4w9WgXcQ", ResourceTypeAuto); err != nil {
+		t.Fatalf("InsertResource failed: %v", err)
+	}
+	if got.Type != ResourceTypeYouTube {
+		t.Errorf("Expected youtube type to be sent, got %q", got.Type)
+	}
+
+	if _, err := client.RAG.InsertResource(ctx, "docs", []byte(`{"a": 1}`), ResourceTypeAuto); err != nil {
+		t.Fatalf("InsertResource failed: %v", err)
+	}
+	if got.Type != ResourceTypeJSON || got.Resource != `{"a": 1}` {
+		t.Errorf("Expected JSON resource to be sent, got %+v", got)
+	}
+
+	if _, err := client.RAG.InsertResource(ctx, "docs", "www.example.com", ResourceTypeAuto); !errors.Is(err, ErrAmbiguousResource) {
+		t.Errorf("Expected ErrAmbiguousResource, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// CollectionExistsError is returned by CreateCollection when a collection
+// with the requested ID already exists.
+type CollectionExistsError struct {
+	CollectionID string
+
+	// The error response of the API
+	Err *APIError
+}
+
+func (e *CollectionExistsError) Error() string {
+	return fmt.Sprintf("collection %s already exists", e.CollectionID)
+}
+
+func (e *CollectionExistsError) Unwrap() error {
+	return e.Err
+}
+
+// asCollectionExists maps an "already exists" response of the create
+// endpoint to a CollectionExistsError. Other errors are returned unchanged.
+func asCollectionExists(collectionID string, err error) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	message := strings.ToLower(apiErr.Message)
+	if apiErr.StatusCode == http.StatusConflict ||
+		(apiErr.StatusCode == http.StatusBadRequest && (strings.Contains(message, "already exist") || strings.Contains(message, "unique"))) {
+		return &CollectionExistsError{CollectionID: collectionID, Err: apiErr}
+	}
+	return err
+}
+
+// EnsureCollectionResponse contains the result of EnsureCollection.
+type EnsureCollectionResponse struct {
+	CollectionID string
+
+	// Whether the collection was created, rather than found
+	Created bool
+}
+
+// EnsureCollection returns the collection with the given ID, creating it if
+// it does not exist. Losing a creation race to another process is not an
+// error: the collection is then reported as found. Concurrent calls for the
+// same ID within a process share a single round of API calls.
+func (c *ragClient) EnsureCollection(ctx context.Context, collectionID string, opts ...CallOption) (EnsureCollectionResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
+	v := newValidator()
+	v.check(collectionID != "", "collection_id", "collection_id should not be empty")
+	if !v.valid() {
+		return EnsureCollectionResponse{}, *newValidationError("Validation Error", v.errors)
+	}
+
+	fullID, err := c.qualify(collectionID)
+	if err != nil {
+		return EnsureCollectionResponse{}, err
+	}
+
+	for {
+		response, err := c.ensuring.do(ctx, fullID, func() (EnsureCollectionResponse, error) {
+			return c.ensureCollection(ctx, collectionID)
+		})
+		// The call was shared with a caller whose context ended; try again
+		// unless ours ended too.
+		if isContextError(err) && ctx.Err() == nil {
+			continue
+		}
+		return response, err
+	}
+}
+
+func (c *ragClient) ensureCollection(ctx context.Context, collectionID string) (EnsureCollectionResponse, error) {
+	found, err := c.GetCollection(ctx, collectionID)
+	if err != nil && !isNotFound(err) {
+		return EnsureCollectionResponse{}, err
+	}
+	if err == nil && found.Found {
+		return EnsureCollectionResponse{CollectionID: collectionID}, nil
+	}
+
+	_, err = c.CreateCollection(ctx, collectionID)
+	var exists *CollectionExistsError
+	if errors.As(err, &exists) {
+		return EnsureCollectionResponse{CollectionID: collectionID}, nil
+	} else if err != nil {
+		return EnsureCollectionResponse{}, err
+	}
+	return EnsureCollectionResponse{CollectionID: collectionID, Created: true}, nil
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// ensureGroup deduplicates concurrent EnsureCollection calls by ID. Callers
+// arriving while a call is in flight wait for its result instead of making
+// their own. The zero value is ready to use.
+type ensureGroup struct {
+	mu    sync.Mutex
+	calls map[string]*ensureCall
+}
+
+type ensureCall struct {
+	done     chan struct{}
+	response EnsureCollectionResponse
+	err      error
+}
+
+func (g *ensureGroup) do(ctx context.Context, key string, fn func() (EnsureCollectionResponse, error)) (EnsureCollectionResponse, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*ensureCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.response, call.err
+		case <-ctx.Done():
+			return EnsureCollectionResponse{}, ctx.Err()
+		}
+	}
+	call := &ensureCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.response, call.err = fn()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(call.done)
+	return call.response, call.err
+}
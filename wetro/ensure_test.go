@@ -0,0 +1,124 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEnsureCollection(t *testing.T) {
+	var mu sync.Mutex
+	collections := map[string]bool{"existing": true}
+	gets, creates := 0, 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v1/collection/get/"):
+			id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/collection/get/"), "/")
+			mu.Lock()
+			gets++
+			found := collections[id]
+			mu.Unlock()
+			if id == "missing-404" {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(map[string]string{"detail": "Not found."})
+				return
+			}
+			json.NewEncoder(w).Encode(GetCollectionResponse{Success: true, Found: found, CollectionID: id})
+		case r.URL.Path == "/v1/collection/create/":
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			id := req["collection_id"]
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			creates++
+			if collections[id] || id == "raced" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "Collection with this ID already exists"})
+				return
+			}
+			collections[id] = true
+			json.NewEncoder(w).Encode(CollectionCreateResponse{Success: true, CollectionID: id})
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+	ctx := context.Background()
+
+	reset := func() {
+		mu.Lock()
+		gets, creates = 0, 0
+		mu.Unlock()
+	}
+
+	t.Run("Existing", func(t *testing.T) {
+		reset()
+		response, err := client.RAG.EnsureCollection(ctx, "existing")
+		if err != nil || response.Created {
+			t.Errorf("Expected the collection to be found, got %+v, %v", response, err)
+		}
+		if creates != 0 {
+			t.Errorf("Expected no create call, got %d", creates)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		reset()
+		var wg sync.WaitGroup
+		errs := make([]error, 10)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, errs[i] = client.RAG.EnsureCollection(ctx, "new")
+			}(i)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				t.Fatalf("EnsureCollection failed: %v", err)
+			}
+		}
+		if creates != 1 || gets != 1 {
+			t.Errorf("Expected concurrent calls to share 1 get and 1 create, got %d and %d", gets, creates)
+		}
+	})
+
+	t.Run("LostRace", func(t *testing.T) {
+		response, err := client.RAG.EnsureCollection(ctx, "raced")
+		if err != nil || response.Created {
+			t.Errorf("Expected an existing collection to be reported as found, got %+v, %v", response, err)
+		}
+	})
+
+	t.Run("NotFoundStatus", func(t *testing.T) {
+		response, err := client.RAG.EnsureCollection(ctx, "missing-404")
+		if err != nil || !response.Created {
+			t.Errorf("Expected the collection to be created, got %+v, %v", response, err)
+		}
+	})
+
+	t.Run("CreateExisting", func(t *testing.T) {
+		_, err := client.RAG.CreateCollection(ctx, "existing")
+		var exists *CollectionExistsError
+		if !errors.As(err, &exists) || exists.CollectionID != "existing" {
+			t.Fatalf("Expected CollectionExistsError, got %v", err)
+		}
+		if exists.Err.Message != "Collection with this ID already exists" {
+			t.Errorf("Expected the API message to be kept, got %q", exists.Err.Message)
+		}
+	})
+}
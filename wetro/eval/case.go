@@ -0,0 +1,104 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+// Package eval measures the answer quality of a collection against a set of
+// golden questions, so that changes to the collection or the model can be
+// compared run over run.
+//
+// Cases are loaded from a JSON or JSON Lines file, run through
+// QueryCollection for one or more models, scored, and summarized in a
+// report that can be written as JSON or Markdown:
+//
+//	cases, err := eval.LoadCases("golden.jsonl")
+//	report, err := eval.Run(ctx, client.RAG, cases, eval.Options{
+//		CollectionID: "my-docs",
+//		Models:       []wetro.ChatModel{wetro.GPT4OMini, wetro.Claude35Haiku20241022},
+//		Scorers:      []eval.Scorer{eval.KeywordScorer{}, eval.NewJudgeScorer(client.Tools, wetro.GPT4O)},
+//	})
+//	report.WriteMarkdown(os.Stdout)
+package eval
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Case is a golden question together with what a good answer looks like.
+type Case struct {
+
+	// (optional) Identifies the case in the report. Defaults to its position.
+	ID string `json:"id,omitempty"`
+
+	Question string `json:"question"`
+
+	// (optional) The expected answer, used by ExactScorer and JudgeScorer
+	Expected string `json:"expected,omitempty"`
+
+	// (optional) Words or phrases a good answer contains, used by KeywordScorer
+	Keywords []string `json:"keywords,omitempty"`
+
+	// (optional) Queries this collection instead of Options.CollectionID
+	CollectionID string `json:"collection_id,omitempty"`
+}
+
+// LoadCases reads cases from a file holding either a JSON array of cases or
+// one case per line (JSON Lines).
+func LoadCases(path string) ([]Case, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	cases, err := ReadCases(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cases, nil
+}
+
+// ReadCases reads cases from r, in either of the formats LoadCases accepts.
+func ReadCases(r io.Reader) ([]Case, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var cases []Case
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &cases); err != nil {
+			return nil, err
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var c Case
+			if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			cases = append(cases, c)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range cases {
+		if cases[i].Question == "" {
+			return nil, fmt.Errorf("case %d: question should not be empty", i+1)
+		}
+		if cases[i].ID == "" {
+			cases[i].ID = fmt.Sprintf("case-%d", i+1)
+		}
+	}
+	return cases, nil
+}
@@ -0,0 +1,154 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Richd0tcom/go-wetro/wetro"
+)
+
+type fakeQuerier struct {
+	answers map[wetro.ChatModel]map[string]string
+}
+
+func (f fakeQuerier) QueryCollection(ctx context.Context, req wetro.QueryRequest, opts ...wetro.CallOption) (wetro.StandardResponse, error) {
+	answer, ok := f.answers[req.Model][req.Query]
+	if !ok {
+		return wetro.StandardResponse{}, errors.New("query failed")
+	}
+	time.Sleep(time.Millisecond)
+	return wetro.StandardResponse{
+		Success:  true,
+		Tokens:   10,
+		Response: map[string]any{"response": answer},
+	}, nil
+}
+
+type fakeJudge struct {
+	reply string
+}
+
+func (f fakeJudge) GenerateText(ctx context.Context, req wetro.TextGenerationRequest, opts ...wetro.CallOption) (wetro.StandardResponse, error) {
+	return wetro.StandardResponse{Success: true, Tokens: 7, Response: f.reply}, nil
+}
+
+func TestLoadCases(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonl := filepath.Join(dir, "cases.jsonl")
+	os.WriteFile(jsonl, []byte(`{"question":"What is Go?","keywords":["language"]}`+"\n\n"+`{"id":"q2","question":"Who made it?","expected":"Google"}`+"\n"), 0o644)
+	cases, err := LoadCases(jsonl)
+	if err != nil {
+		t.Fatalf("LoadCases failed: %v", err)
+	}
+	if len(cases) != 2 || cases[0].ID != "case-1" || cases[1].ID != "q2" || cases[1].Expected != "Google" {
+		t.Errorf("Unexpected cases %+v", cases)
+	}
+
+	array := filepath.Join(dir, "cases.json")
+	os.WriteFile(array, []byte(` [{"question":"What is Go?"}]`), 0o644)
+	if cases, err := LoadCases(array); err != nil || len(cases) != 1 {
+		t.Errorf("Expected one case from a JSON array, got %v, %v", cases, err)
+	}
+
+	if _, err := ReadCases(strings.NewReader(`{"expected":"x"}`)); err == nil {
+		t.Error("Expected an error for a case without a question")
+	}
+}
+
+func TestScorers(t *testing.T) {
+	ctx := context.Background()
+	c := Case{Question: "Who made Go?", Expected: "Google", Keywords: []string{"google", "2009"}}
+
+	if s, _ := (ExactScorer{}).Score(ctx, c, "  google "); s.Value != 1 {
+		t.Errorf("Expected an exact match, got %+v", s)
+	}
+	if s, _ := (ExactScorer{CaseSensitive: true}).Score(ctx, c, "google"); s.Value != 0 {
+		t.Errorf("Expected a case sensitive mismatch, got %+v", s)
+	}
+	if s, _ := (ExactScorer{}).Score(ctx, Case{Question: "q"}, "a"); !s.Skipped {
+		t.Error("Expected cases without an expected answer to be skipped")
+	}
+
+	s, _ := (KeywordScorer{}).Score(ctx, c, "Go was made at Google.")
+	if s.Value != 0.5 || s.Reason != "missing 2009" {
+		t.Errorf("Expected half the keywords, got %+v", s)
+	}
+
+	judge := NewJudgeScorer(fakeJudge{reply: "SCORE: 8/10\nREASON: Mostly right."}, wetro.GPT4O)
+	s, err := judge.Score(ctx, c, "Google")
+	if err != nil {
+		t.Fatalf("Judge failed: %v", err)
+	}
+	if s.Value != 0.8 || s.Reason != "Mostly right." || s.Tokens != 7 {
+		t.Errorf("Unexpected judgement %+v", s)
+	}
+
+	if _, err := NewJudgeScorer(fakeJudge{reply: "looks fine"}, "").Score(ctx, c, "Google"); err == nil {
+		t.Error("Expected an error for a reply without a score")
+	}
+}
+
+func TestRun(t *testing.T) {
+	querier := fakeQuerier{answers: map[wetro.ChatModel]map[string]string{
+		wetro.GPT4OMini: {"Who made Go?": "Google", "When?": "In 2009"},
+		wetro.GPT4O:     {"Who made Go?": "Bell Labs"},
+	}}
+	cases := []Case{
+		{ID: "maker", Question: "Who made Go?", Expected: "Google"},
+		{ID: "year", Question: "When?", Keywords: []string{"2009"}},
+	}
+
+	report, err := Run(context.Background(), querier, cases, Options{
+		CollectionID: "docs",
+		Models:       []wetro.ChatModel{wetro.GPT4OMini, wetro.GPT4O},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(report.Results) != 4 || report.Results[2].Model != wetro.GPT4O || report.Results[2].CaseID != "maker" {
+		t.Fatalf("Unexpected results %+v", report.Results)
+	}
+	mini, gpt4o := report.Models[0], report.Models[1]
+	if mini.Passed != 2 || mini.PassRate != 1 || mini.Tokens != 20 || mini.MeanScores["exact"] != 1 {
+		t.Errorf("Unexpected stats for %s: %+v", mini.Model, mini)
+	}
+	if gpt4o.Passed != 0 || gpt4o.Errors != 1 || gpt4o.MeanScores["exact"] != 0 {
+		t.Errorf("Unexpected stats for %s: %+v", gpt4o.Model, gpt4o)
+	}
+	if mini.Latency.Max < time.Millisecond || mini.Latency.P50 > mini.Latency.Max {
+		t.Errorf("Unexpected latency stats %+v", mini.Latency)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Results) != 4 {
+		t.Errorf("Expected the JSON report to round trip, got %v", err)
+	}
+
+	buf.Reset()
+	if err := report.WriteMarkdown(&buf); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	md := buf.String()
+	for _, want := range []string{"| gpt-4o-mini | 2 | 2 (100%) | 0 | 1.00 | 1.00 |", "| maker | gpt-4o | 0.00 | no |", "query failed"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected the Markdown report to contain %q:\n%s", want, md)
+		}
+	}
+
+	if _, err := Run(context.Background(), querier, cases, Options{}); err == nil {
+		t.Error("Expected an error without a collection ID")
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Richd0tcom/go-wetro/wetro"
+)
+
+// Report summarizes an evaluation run.
+type Report struct {
+	CollectionID string        `json:"collection_id,omitempty"`
+	StartedAt    time.Time     `json:"started_at"`
+	Duration     time.Duration `json:"duration"`
+
+	// Aggregated statistics, one entry per model in the order they were run
+	Models []ModelStats `json:"models"`
+
+	// Every case run against every model
+	Results []Result `json:"results"`
+}
+
+// ModelStats aggregates the results of a model.
+type ModelStats struct {
+	Model  wetro.ChatModel `json:"model,omitempty"`
+	Cases  int             `json:"cases"`
+	Passed int             `json:"passed"`
+	Errors int             `json:"errors"`
+
+	// Share of the cases that passed
+	PassRate float64 `json:"pass_rate"`
+
+	// Mean score by scorer name, over the cases the scorer did not skip
+	MeanScores map[string]float64 `json:"mean_scores"`
+
+	Latency LatencyStats `json:"latency"`
+
+	// Tokens consumed by the queries, in total and per case
+	Tokens     int     `json:"tokens"`
+	MeanTokens float64 `json:"mean_tokens"`
+
+	// Tokens consumed by the scorers
+	ScoringTokens int `json:"scoring_tokens"`
+}
+
+// LatencyStats describes the distribution of query latencies.
+type LatencyStats struct {
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P95  time.Duration `json:"p95"`
+	Max  time.Duration `json:"max"`
+}
+
+func newReport(opts Options, results []Result, start time.Time) *Report {
+	report := &Report{
+		CollectionID: opts.CollectionID,
+		StartedAt:    start,
+		Duration:     time.Since(start),
+		Results:      results,
+	}
+
+	for _, model := range opts.Models {
+		stats := ModelStats{Model: model, MeanScores: make(map[string]float64)}
+		counts := make(map[string]int)
+		var latencies []time.Duration
+
+		for _, r := range results {
+			if r.Model != model {
+				continue
+			}
+			stats.Cases++
+			stats.Tokens += r.Tokens
+			stats.ScoringTokens += r.ScoringTokens
+			latencies = append(latencies, r.Latency)
+			if r.Error != "" {
+				stats.Errors++
+				continue
+			}
+			if r.Passed {
+				stats.Passed++
+			}
+			for name, score := range r.Scores {
+				if !score.Skipped {
+					stats.MeanScores[name] += score.Value
+					counts[name]++
+				}
+			}
+		}
+
+		for name, n := range counts {
+			stats.MeanScores[name] /= float64(n)
+		}
+		if stats.Cases > 0 {
+			stats.PassRate = float64(stats.Passed) / float64(stats.Cases)
+			stats.MeanTokens = float64(stats.Tokens) / float64(stats.Cases)
+		}
+		stats.Latency = latencyStats(latencies)
+		report.Models = append(report.Models, stats)
+	}
+	return report
+}
+
+func latencyStats(latencies []time.Duration) LatencyStats {
+	if len(latencies) == 0 {
+		return LatencyStats{}
+	}
+	sorted := append([]time.Duration{}, latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+	}
+	percentile := func(p float64) time.Duration {
+		return sorted[int(p*float64(len(sorted)-1)+0.5)]
+	}
+	return LatencyStats{
+		Mean: total / time.Duration(len(sorted)),
+		P50:  percentile(0.50),
+		P95:  percentile(0.95),
+		Max:  sorted[len(sorted)-1],
+	}
+}
+
+// WriteJSON writes the report as indented JSON. Durations are in nanoseconds.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteMarkdown writes the report as Markdown tables: a summary per model
+// followed by the result of every case.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	scorers := r.scorerNames()
+
+	fmt.Fprintf(&b, "# Evaluation report\n\n")
+	if r.CollectionID != "" {
+		fmt.Fprintf(&b, "Collection: `%s`  \n", r.CollectionID)
+	}
+	fmt.Fprintf(&b, "Started: %s  \nDuration: %s\n\n", r.StartedAt.Format(time.RFC3339), r.Duration.Round(time.Millisecond))
+
+	fmt.Fprintf(&b, "## Summary\n\n| Model | Cases | Passed | Errors |")
+	for _, name := range scorers {
+		fmt.Fprintf(&b, " %s |", name)
+	}
+	fmt.Fprintf(&b, " Latency p50 | Latency p95 | Tokens | Scoring tokens |\n|---|---|---|---|")
+	b.WriteString(strings.Repeat("---|", len(scorers)+4) + "\n")
+	for _, m := range r.Models {
+		fmt.Fprintf(&b, "| %s | %d | %d (%.0f%%) | %d |", modelName(m.Model), m.Cases, m.Passed, m.PassRate*100, m.Errors)
+		for _, name := range scorers {
+			if mean, ok := m.MeanScores[name]; ok {
+				fmt.Fprintf(&b, " %.2f |", mean)
+			} else {
+				b.WriteString(" - |")
+			}
+		}
+		fmt.Fprintf(&b, " %s | %s | %d | %d |\n", m.Latency.P50.Round(time.Millisecond), m.Latency.P95.Round(time.Millisecond), m.Tokens, m.ScoringTokens)
+	}
+
+	fmt.Fprintf(&b, "\n## Results\n\n| Case | Model | Score | Passed | Latency | Tokens | Notes |\n|---|---|---|---|---|---|---|\n")
+	for _, res := range r.Results {
+		passed := "no"
+		if res.Passed {
+			passed = "yes"
+		}
+		notes := res.Error
+		if notes == "" {
+			notes = res.notes(scorers)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %.2f | %s | %s | %d | %s |\n",
+			escapeCell(res.CaseID), modelName(res.Model), res.Score, passed,
+			res.Latency.Round(time.Millisecond), res.Tokens, escapeCell(notes))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// scorerNames returns the names of every scorer used in the run, sorted.
+func (r *Report) scorerNames() []string {
+	seen := make(map[string]bool)
+	for _, res := range r.Results {
+		for name := range res.Scores {
+			seen[name] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r Result) notes(scorers []string) string {
+	var notes []string
+	for _, name := range scorers {
+		if score, ok := r.Scores[name]; ok && !score.Skipped && score.Reason != "" {
+			notes = append(notes, name+": "+score.Reason)
+		}
+	}
+	return strings.Join(notes, "; ")
+}
+
+func modelName(model wetro.ChatModel) string {
+	if model == "" {
+		return "(default)"
+	}
+	return string(model)
+}
+
+func escapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
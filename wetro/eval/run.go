@@ -0,0 +1,164 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package eval
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Richd0tcom/go-wetro/wetro"
+)
+
+const (
+	defaultConcurrency   = 4
+	defaultPassThreshold = 0.5
+)
+
+// Querier queries a collection, as client.RAG does.
+type Querier interface {
+	QueryCollection(ctx context.Context, request wetro.QueryRequest, opts ...wetro.CallOption) (wetro.StandardResponse, error)
+}
+
+// Options configures an evaluation run.
+type Options struct {
+
+	// The collection the cases are run against, unless they name their own
+	CollectionID string
+
+	// (optional) Run every case once per model. Empty runs the cases once
+	// with the default model of the API.
+	Models []wetro.ChatModel
+
+	// Scorers grading every answer. Defaults to ExactScorer and KeywordScorer.
+	Scorers []Scorer
+
+	// Maximum number of cases run at the same time. Defaults to 4.
+	Concurrency int
+
+	// Mean score from which a case counts as passed. Defaults to 0.5.
+	PassThreshold float64
+}
+
+// Result is the outcome of running a single case against a single model.
+type Result struct {
+	CaseID   string          `json:"case_id"`
+	Question string          `json:"question"`
+	Model    wetro.ChatModel `json:"model,omitempty"`
+	Answer   string          `json:"answer"`
+
+	// Scores by scorer name
+	Scores map[string]Score `json:"scores,omitempty"`
+
+	// Mean of the scores that were not skipped
+	Score  float64 `json:"score"`
+	Passed bool    `json:"passed"`
+
+	// Time taken by QueryCollection
+	Latency time.Duration `json:"latency"`
+
+	// Tokens consumed by QueryCollection
+	Tokens int `json:"tokens"`
+
+	// Tokens consumed by the scorers
+	ScoringTokens int `json:"scoring_tokens,omitempty"`
+
+	// Why the case could not be run or scored
+	Error string `json:"error,omitempty"`
+}
+
+// Run runs every case against every model in opts and scores the answers.
+// A failing case is recorded in its Result and does not stop the run; an
+// error is only returned for invalid options or a canceled context.
+func Run(ctx context.Context, q Querier, cases []Case, opts Options) (*Report, error) {
+	if len(cases) == 0 {
+		return nil, errors.New("no cases to run")
+	}
+	for _, c := range cases {
+		if c.CollectionID == "" && opts.CollectionID == "" {
+			return nil, errors.New("a collection ID is required for case " + c.ID)
+		}
+	}
+	if len(opts.Models) == 0 {
+		opts.Models = []wetro.ChatModel{""}
+	}
+	if len(opts.Scorers) == 0 {
+		opts.Scorers = []Scorer{ExactScorer{}, KeywordScorer{}}
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+	if opts.PassThreshold <= 0 {
+		opts.PassThreshold = defaultPassThreshold
+	}
+
+	start := time.Now()
+	results := make([]Result, len(opts.Models)*len(cases))
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for m, model := range opts.Models {
+		for i, c := range cases {
+			wg.Add(1)
+			go func(index int, model wetro.ChatModel, c Case) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				results[index] = runCase(ctx, q, c, model, opts)
+			}(m*len(cases)+i, model, c)
+		}
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return newReport(opts, results, start), nil
+}
+
+func runCase(ctx context.Context, q Querier, c Case, model wetro.ChatModel, opts Options) Result {
+	result := Result{CaseID: c.ID, Question: c.Question, Model: model}
+
+	collectionID := c.CollectionID
+	if collectionID == "" {
+		collectionID = opts.CollectionID
+	}
+
+	start := time.Now()
+	response, err := q.QueryCollection(ctx, wetro.QueryRequest{
+		CollectionID: collectionID,
+		Query:        c.Question,
+		Model:        model,
+	})
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Answer = response.Text()
+	result.Tokens = response.Tokens
+
+	result.Scores = make(map[string]Score, len(opts.Scorers))
+	var total float64
+	var scored int
+	for _, scorer := range opts.Scorers {
+		score, err := scorer.Score(ctx, c, result.Answer)
+		result.ScoringTokens += score.Tokens
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.Scores[scorer.Name()] = score
+		if !score.Skipped {
+			total += score.Value
+			scored++
+		}
+	}
+	if scored > 0 {
+		result.Score = total / float64(scored)
+		result.Passed = result.Score >= opts.PassThreshold
+	}
+	return result
+}
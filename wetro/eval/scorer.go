@@ -0,0 +1,178 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package eval
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Richd0tcom/go-wetro/wetro"
+)
+
+// Score is the grade a scorer gives an answer.
+type Score struct {
+
+	// The grade, from 0 (wrong) to 1 (correct)
+	Value float64 `json:"value"`
+
+	// (optional) Why the answer got this grade
+	Reason string `json:"reason,omitempty"`
+
+	// Tokens consumed to compute the score
+	Tokens int `json:"tokens,omitempty"`
+
+	// Set when the case has nothing this scorer can grade against. Skipped
+	// scores do not count towards the statistics.
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// Scorer grades the answer to a case.
+type Scorer interface {
+	Name() string
+	Score(ctx context.Context, c Case, answer string) (Score, error)
+}
+
+// ExactScorer gives 1 to answers equal to Case.Expected, ignoring case and
+// surrounding or repeated whitespace, and 0 to any other answer.
+type ExactScorer struct {
+	CaseSensitive bool
+}
+
+// Name implements Scorer.
+func (s ExactScorer) Name() string { return "exact" }
+
+// Score implements Scorer.
+func (s ExactScorer) Score(ctx context.Context, c Case, answer string) (Score, error) {
+	if c.Expected == "" {
+		return Score{Skipped: true}, nil
+	}
+	if normalize(answer, s.CaseSensitive) == normalize(c.Expected, s.CaseSensitive) {
+		return Score{Value: 1}, nil
+	}
+	return Score{Reason: "answer differs from the expected answer"}, nil
+}
+
+// KeywordScorer grades an answer by the share of Case.Keywords it contains.
+type KeywordScorer struct {
+	CaseSensitive bool
+}
+
+// Name implements Scorer.
+func (s KeywordScorer) Name() string { return "keyword" }
+
+// Score implements Scorer.
+func (s KeywordScorer) Score(ctx context.Context, c Case, answer string) (Score, error) {
+	if len(c.Keywords) == 0 {
+		return Score{Skipped: true}, nil
+	}
+
+	answer = normalize(answer, s.CaseSensitive)
+	var missing []string
+	for _, keyword := range c.Keywords {
+		if !strings.Contains(answer, normalize(keyword, s.CaseSensitive)) {
+			missing = append(missing, keyword)
+		}
+	}
+
+	score := Score{Value: float64(len(c.Keywords)-len(missing)) / float64(len(c.Keywords))}
+	if len(missing) > 0 {
+		score.Reason = "missing " + strings.Join(missing, ", ")
+	}
+	return score, nil
+}
+
+func normalize(s string, caseSensitive bool) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if !caseSensitive {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// TextGenerator generates text from chat messages, as client.Tools does.
+type TextGenerator interface {
+	GenerateText(ctx context.Context, payload wetro.TextGenerationRequest, opts ...wetro.CallOption) (wetro.StandardResponse, error)
+}
+
+const defaultJudgePrompt = "You grade answers to questions. Compare the answer with the expected answer " +
+	"and rate how correct and complete it is from 0 (wrong) to 10 (fully correct). " +
+	"Reply with the grade on the first line as \"SCORE: <0-10>\" and a one sentence reason on the second line."
+
+// JudgeScorer asks a model to grade answers against Case.Expected, or
+// against Case.Keywords when there is no expected answer.
+type JudgeScorer struct {
+	generator TextGenerator
+
+	// (optional) The judging model
+	Model wetro.ChatModel
+
+	// (optional) Instructions for the judging model, replacing the default.
+	// The model must reply with "SCORE: <0-10>" followed by its reason.
+	Prompt string
+}
+
+// NewJudgeScorer returns a scorer using generator, typically client.Tools,
+// to have model grade the answers.
+func NewJudgeScorer(generator TextGenerator, model wetro.ChatModel) *JudgeScorer {
+	return &JudgeScorer{generator: generator, Model: model}
+}
+
+// Name implements Scorer.
+func (s *JudgeScorer) Name() string { return "judge" }
+
+// Score implements Scorer.
+func (s *JudgeScorer) Score(ctx context.Context, c Case, answer string) (Score, error) {
+	expected := c.Expected
+	if expected == "" && len(c.Keywords) > 0 {
+		expected = "An answer mentioning: " + strings.Join(c.Keywords, ", ")
+	}
+	if expected == "" {
+		return Score{Skipped: true}, nil
+	}
+
+	prompt := s.Prompt
+	if prompt == "" {
+		prompt = defaultJudgePrompt
+	}
+	response, err := s.generator.GenerateText(ctx, wetro.TextGenerationRequest{
+		Messages: []wetro.MessageObject{
+			{Role: wetro.RoleSystem, Content: prompt},
+			{Role: wetro.RoleUser, Content: fmt.Sprintf("Question: %s\n\nExpected answer: %s\n\nAnswer: %s", c.Question, expected, answer)},
+		},
+		Model: s.Model,
+	})
+	if err != nil {
+		return Score{}, fmt.Errorf("judge: %w", err)
+	}
+
+	score, err := parseJudgement(response.Text())
+	score.Tokens = response.Tokens
+	return score, err
+}
+
+var judgeScore = regexp.MustCompile(`(?i)score\s*:?\s*(\d+(?:\.\d+)?)`)
+
+func parseJudgement(text string) (Score, error) {
+	match := judgeScore.FindStringSubmatchIndex(text)
+	if match == nil {
+		return Score{}, fmt.Errorf("judge: no score in reply %q", text)
+	}
+	grade, err := strconv.ParseFloat(text[match[2]:match[3]], 64)
+	if err != nil {
+		return Score{}, fmt.Errorf("judge: %w", err)
+	}
+	if grade > 10 {
+		grade = 10
+	}
+	reason := strings.TrimSpace(text[match[1]:])
+	reason = strings.TrimSpace(strings.TrimPrefix(reason, "/10"))
+	if r, ok := strings.CutPrefix(strings.ToLower(reason), "reason:"); ok {
+		reason = strings.TrimSpace(reason[len(reason)-len(r):])
+	}
+	return Score{Value: grade / 10, Reason: reason}, nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultExpiryInterval = time.Minute
+	defaultExpiryLease    = 5 * time.Minute
+)
+
+// WithExpiryStore sets the store InsertResourceWithTTL records expiring
+// resources in, and the ExpiryRunner removes them from.
+func WithExpiryStore(store ExpiryStore) ClientOption {
+	return func(c *apiClient) {
+		c.expiries = store
+	}
+}
+
+// InsertResourceWithTTL inserts a resource like InsertResource and records
+// it in the expiry store to be removed once ttl has elapsed, by an
+// ExpiryRunner. If the resource is inserted but cannot be tracked, the
+// response is returned together with the store error.
+//
+// InsertResourceWithTTL requires an expiry store, see WithExpiryStore.
+func (c *ragClient) InsertResourceWithTTL(ctx context.Context, collectionID string, resource any, resourceType ResourceType, ttl time.Duration, opts ...CallOption) (ResourceInsertResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
+	if c.client.expiries == nil {
+		return ResourceInsertResponse{}, errors.New("inserting a resource with a TTL requires an expiry store")
+	}
+	v := newValidator()
+	v.check(ttl > 0, "ttl", "ttl should be positive")
+	if !v.valid() {
+		return ResourceInsertResponse{}, *newValidationError("Validation Error", v.errors)
+	}
+
+	fullID, err := c.qualify(collectionID)
+	if err != nil {
+		return ResourceInsertResponse{}, err
+	}
+	response, err := c.InsertResource(ctx, collectionID, resource, resourceType, idempotencyOptions(ctx, "")...)
+	if err != nil {
+		return ResourceInsertResponse{}, err
+	}
+
+	err = c.client.expiries.Track(ctx, ExpiryEntry{
+		CollectionID: fullID,
+		ResourceID:   response.ResourceID,
+		ExpiresAt:    time.Now().Add(ttl).UTC(),
+	})
+	if err != nil {
+		return response, fmt.Errorf("resource %s not tracked for expiry: %w", response.ResourceID, err)
+	}
+	return response, nil
+}
+
+// ExpiryRunnerOptions configures an ExpiryRunner.
+type ExpiryRunnerOptions struct {
+
+	// Time between two passes of Run. Defaults to 1 minute.
+	Interval time.Duration
+
+	// How long a runner holds on to an entry it is removing before other
+	// runners may take it over. Defaults to 5 minutes.
+	Lease time.Duration
+
+	// (optional) Called after every expired resource is processed, with the
+	// error it failed with or nil.
+	OnExpire func(ExpiryEntry, error)
+
+	// (optional) Called by Run with the error of a pass that failed, after
+	// which Run carries on with the next pass. Without it, Run returns the
+	// error.
+	OnError func(error)
+}
+
+// ExpiryRunner removes expired resources recorded by InsertResourceWithTTL.
+// Entries stay in the store until their resource is removed, so a runner
+// that is restarted picks up where it stopped. Several runners may share a
+// store: each entry is leased by one runner at a time.
+type ExpiryRunner struct {
+	rag  *ragClient
+	opts ExpiryRunnerOptions
+}
+
+// ExpiryResult summarizes a pass of an ExpiryRunner.
+type ExpiryResult struct {
+
+	// Resources removed
+	Removed int
+
+	// Expired entries leased by another runner
+	Skipped int
+
+	// Resources that could not be removed and are retried on the next pass
+	Failed int
+}
+
+// NewExpiryRunner returns a runner removing the expired resources of the
+// client's expiry store, see WithExpiryStore. The store holds full
+// collection IDs, so a runner removes expired resources of every namespace.
+func (c *ragClient) NewExpiryRunner(opts ExpiryRunnerOptions) *ExpiryRunner {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultExpiryInterval
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = defaultExpiryLease
+	}
+	return &ExpiryRunner{rag: c.root(), opts: opts}
+}
+
+// Run removes expired resources every Interval until ctx is done, and then
+// returns the context error. A pass that fails stops the runner with its
+// error, unless OnError is set. Resources that could not be removed are not
+// errors of the pass; they are reported to OnExpire and retried.
+func (r *ExpiryRunner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.RunOnce(ctx); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if r.opts.OnError == nil {
+				return err
+			}
+			r.opts.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce removes the resources that have expired so far. A resource that
+// is already gone counts as removed.
+func (r *ExpiryRunner) RunOnce(ctx context.Context) (ExpiryResult, error) {
+	store := r.rag.client.expiries
+	if store == nil {
+		return ExpiryResult{}, errors.New("running expiries requires an expiry store")
+	}
+
+	due, err := store.Due(ctx, time.Now())
+	if err != nil {
+		return ExpiryResult{}, err
+	}
+
+	var result ExpiryResult
+	for _, entry := range due {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		claimed, err := store.Claim(ctx, entry, r.opts.Lease)
+		if err != nil {
+			return result, err
+		}
+		if !claimed {
+			result.Skipped++
+			continue
+		}
+
+		err = r.expire(ctx, store, entry)
+		if err != nil {
+			result.Failed++
+		} else {
+			result.Removed++
+		}
+		if r.opts.OnExpire != nil {
+			r.opts.OnExpire(entry, err)
+		}
+	}
+	return result, nil
+}
+
+func (r *ExpiryRunner) expire(ctx context.Context, store ExpiryStore, entry ExpiryEntry) error {
+	_, err := r.rag.RemoveResource(ctx, ResourceDeleteRequest{
+		CollectionID: entry.CollectionID,
+		ResourceID:   entry.ResourceID,
+	})
+	if err != nil && !isNotFound(err) {
+		if releaseErr := store.Release(ctx, entry); releaseErr != nil {
+			err = errors.Join(err, releaseErr)
+		}
+		return err
+	}
+	return store.Remove(ctx, entry)
+}
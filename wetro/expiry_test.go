@@ -0,0 +1,251 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExpiryStores(t *testing.T) {
+	ctx := context.Background()
+	fileStore, err := NewFileExpiryStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileExpiryStore failed: %v", err)
+	}
+
+	for name, store := range map[string]ExpiryStore{
+		"Memory": NewMemoryExpiryStore(),
+		"File":   fileStore,
+	} {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			late := ExpiryEntry{CollectionID: "news", ResourceID: "a", ExpiresAt: now.Add(-time.Minute)}
+			early := ExpiryEntry{CollectionID: "news", ResourceID: "b", ExpiresAt: now.Add(-time.Hour)}
+			future := ExpiryEntry{CollectionID: "news", ResourceID: "c", ExpiresAt: now.Add(time.Hour)}
+			for _, e := range []ExpiryEntry{late, early, future} {
+				if err := store.Track(ctx, e); err != nil {
+					t.Fatalf("Track failed: %v", err)
+				}
+			}
+
+			due, err := store.Due(ctx, now)
+			if err != nil {
+				t.Fatalf("Due failed: %v", err)
+			}
+			if len(due) != 2 || due[0].ResourceID != "b" || due[1].ResourceID != "a" {
+				t.Errorf("Expected the 2 expired entries oldest first, got %+v", due)
+			}
+
+			if ok, _ := store.Claim(ctx, late, time.Hour); !ok {
+				t.Error("Expected the first claim to succeed")
+			}
+			if ok, _ := store.Claim(ctx, late, time.Hour); ok {
+				t.Error("Expected a held lease to block other claims")
+			}
+			store.Release(ctx, late)
+			if ok, _ := store.Claim(ctx, late, -time.Second); !ok {
+				t.Error("Expected a released entry to be claimable")
+			}
+			if ok, _ := store.Claim(ctx, late, time.Hour); !ok {
+				t.Error("Expected an expired lease to be taken over")
+			}
+
+			store.Remove(ctx, late)
+			due, _ = store.Due(ctx, now)
+			if len(due) != 1 || due[0].ResourceID != "b" {
+				t.Errorf("Expected the removed entry to be gone, got %+v", due)
+			}
+		})
+	}
+}
+
+func TestExpiryRunner(t *testing.T) {
+	var mu sync.Mutex
+	removed := map[string]int{}
+	failOnce := true
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/v1/resource/insert/":
+			var req ResourceInsertRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(ResourceInsertResponse{Success: true, ResourceID: req.Resource})
+		case "/v1/resource/remove/":
+			var req ResourceDeleteRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			switch {
+			case req.ResourceID == "flaky" && failOnce:
+				failOnce = false
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{"error": "boom"})
+				return
+			case req.ResourceID == "gone":
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(map[string]string{"detail": "Not found."})
+				return
+			}
+			removed[req.ResourceID]++
+			json.NewEncoder(w).Encode(ResourceDeleteResponse{Success: true})
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	newClient := func() *Client {
+		store, err := NewFileExpiryStore(dir)
+		if err != nil {
+			t.Fatalf("NewFileExpiryStore failed: %v", err)
+		}
+		return NewClient("test-api-key", WithExpiryStore(store), func(c *apiClient) {
+			c.baseURL = server.URL + "/"
+		})
+	}
+	ctx := context.Background()
+
+	client := newClient()
+	for _, name := range []string{"article", "flaky", "gone"} {
+		if _, err := client.RAG.InsertResourceWithTTL(ctx, "news", name, ResourceTypeText, time.Millisecond); err != nil {
+			t.Fatalf("InsertResourceWithTTL failed: %v", err)
+		}
+	}
+	client.RAG.InsertResourceWithTTL(ctx, "news", "fresh", ResourceTypeText, time.Hour)
+	if _, err := client.RAG.InsertResourceWithTTL(ctx, "news", "x", ResourceTypeText, 0); err == nil {
+		t.Error("Expected an error for a zero TTL")
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// Two runners, as if in two processes sharing the directory
+	runners := []*ExpiryRunner{
+		client.RAG.NewExpiryRunner(ExpiryRunnerOptions{}),
+		newClient().RAG.NewExpiryRunner(ExpiryRunnerOptions{}),
+	}
+	var wg sync.WaitGroup
+	results := make([]ExpiryResult, len(runners))
+	for i, runner := range runners {
+		wg.Add(1)
+		go func(i int, runner *ExpiryRunner) {
+			defer wg.Done()
+			results[i], _ = runner.RunOnce(ctx)
+		}(i, runner)
+	}
+	wg.Wait()
+
+	if results[0].Failed+results[1].Failed != 1 {
+		t.Errorf("Expected 1 failed removal, got %+v", results)
+	}
+
+	// A restarted runner retries whatever is left
+	var expired []string
+	restarted := newClient().RAG.NewExpiryRunner(ExpiryRunnerOptions{
+		OnExpire: func(e ExpiryEntry, err error) { expired = append(expired, e.ResourceID) },
+	})
+	result, err := restarted.RunOnce(ctx)
+	if err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	if total := results[0].Removed + results[1].Removed + result.Removed; total != 3 || len(expired) != result.Removed {
+		t.Errorf("Expected 3 removals in total, got %+v and %+v", results, result)
+	}
+	if removed["article"] != 1 || removed["flaky"] != 1 || removed["fresh"] != 0 {
+		t.Errorf("Expected each expired resource to be removed once, got %v", removed)
+	}
+
+	entries, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	leases, _ := filepath.Glob(filepath.Join(dir, "*.lease"))
+	if len(entries) != 1 || len(leases) != 0 {
+		t.Errorf("Expected only the unexpired entry to be left, got %v and %v", entries, leases)
+	}
+
+	// Run stops on the error of a pass, unless told to carry on
+	broken := client.RAG.NewExpiryRunner(ExpiryRunnerOptions{})
+	broken.rag = NewClient("test-api-key").RAG
+	if err := broken.Run(ctx); err == nil {
+		t.Error("Expected Run to return the error of a failed pass")
+	}
+	var errs int
+	broken.opts.OnError = func(error) { errs++ }
+	runCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	broken.opts.Interval = time.Millisecond
+	if err := broken.Run(runCtx); err != context.DeadlineExceeded || errs < 2 {
+		t.Errorf("Expected Run to report errors and carry on, got %v after %d errors", err, errs)
+	}
+}
+
+func TestFileExpiryStoreLeases(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	newStore := func() *FileExpiryStore {
+		store, err := NewFileExpiryStore(dir)
+		if err != nil {
+			t.Fatalf("NewFileExpiryStore failed: %v", err)
+		}
+		return store
+	}
+	first, second := newStore(), newStore()
+
+	entry := ExpiryEntry{CollectionID: "news", ResourceID: "a", ExpiresAt: time.Now()}
+	first.Track(ctx, entry)
+
+	// A lease that cannot be read is left alone
+	os.WriteFile(first.leasePath(entry), nil, 0o644)
+	if ok, err := first.Claim(ctx, entry, time.Hour); ok || err != nil {
+		t.Errorf("Expected an unreadable lease to count as held, got %v, %v", ok, err)
+	}
+	os.Remove(first.leasePath(entry))
+
+	// Only one of many concurrent claims succeeds
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	claims := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _ := newStore().Claim(ctx, entry, time.Hour); ok {
+				mu.Lock()
+				claims++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if claims != 1 {
+		t.Errorf("Expected exactly 1 claim to succeed, got %d", claims)
+	}
+	os.Remove(first.leasePath(entry))
+
+	// A runner whose lease was taken over leaves the entry to the new owner
+	if ok, _ := first.Claim(ctx, entry, -time.Second); !ok {
+		t.Fatal("Expected the first claim to succeed")
+	}
+	if ok, _ := second.Claim(ctx, entry, time.Hour); !ok {
+		t.Fatal("Expected the expired lease to be taken over")
+	}
+	if err := first.Release(ctx, entry); !errors.Is(err, errLeaseLost) {
+		t.Errorf("Expected errLeaseLost on Release, got %v", err)
+	}
+	if err := first.Remove(ctx, entry); !errors.Is(err, errLeaseLost) {
+		t.Errorf("Expected errLeaseLost on Remove, got %v", err)
+	}
+	if due, _ := second.Due(ctx, time.Now()); len(due) != 1 {
+		t.Error("Expected the entry to be kept for the new owner")
+	}
+	if err := second.Remove(ctx, entry); err != nil {
+		t.Errorf("Expected the owner to remove the entry, got %v", err)
+	}
+	if due, _ := second.Due(ctx, time.Now()); len(due) != 0 {
+		t.Errorf("Expected the entry to be removed, got %+v", due)
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExpiryEntry records when a resource should be removed from its collection.
+type ExpiryEntry struct {
+	CollectionID string    `json:"collection_id"`
+	ResourceID   string    `json:"resource_id"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (e ExpiryEntry) key() string {
+	sum := sha256.Sum256([]byte(e.CollectionID + "\x00" + e.ResourceID))
+	return hex.EncodeToString(sum[:16])
+}
+
+// ExpiryStore tracks resources that expire. Runners in several processes can
+// share a store: an entry is claimed with a lease before it is processed, so
+// that only one runner removes a given resource at a time.
+type ExpiryStore interface {
+
+	// Track records an entry, replacing an earlier entry for the same resource.
+	Track(ctx context.Context, entry ExpiryEntry) error
+
+	// Due returns the entries that expire at or before now, oldest first.
+	Due(ctx context.Context, now time.Time) ([]ExpiryEntry, error)
+
+	// Claim takes a lease on an entry for the given duration. It returns
+	// false if another runner holds an unexpired lease on it, or if the
+	// entry was removed in the meantime.
+	Claim(ctx context.Context, entry ExpiryEntry, lease time.Duration) (bool, error)
+
+	// Release gives up the lease on an entry so it is retried later.
+	Release(ctx context.Context, entry ExpiryEntry) error
+
+	// Remove stops tracking an entry and drops its lease.
+	Remove(ctx context.Context, entry ExpiryEntry) error
+}
+
+// MemoryExpiryStore is an ExpiryStore that keeps entries in memory.
+// It is safe for concurrent use.
+type MemoryExpiryStore struct {
+	mu      sync.Mutex
+	entries map[string]ExpiryEntry
+	leases  map[string]time.Time
+}
+
+// NewMemoryExpiryStore returns an empty in-memory expiry store.
+func NewMemoryExpiryStore() *MemoryExpiryStore {
+	return &MemoryExpiryStore{
+		entries: make(map[string]ExpiryEntry),
+		leases:  make(map[string]time.Time),
+	}
+}
+
+// Track implements ExpiryStore.
+func (s *MemoryExpiryStore) Track(ctx context.Context, entry ExpiryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[entry.key()] = entry
+	return nil
+}
+
+// Due implements ExpiryStore.
+func (s *MemoryExpiryStore) Due(ctx context.Context, now time.Time) ([]ExpiryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []ExpiryEntry
+	for _, entry := range s.entries {
+		if !entry.ExpiresAt.After(now) {
+			due = append(due, entry)
+		}
+	}
+	sortExpiryEntries(due)
+	return due, nil
+}
+
+// Claim implements ExpiryStore.
+func (s *MemoryExpiryStore) Claim(ctx context.Context, entry ExpiryEntry, lease time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := entry.key()
+	if _, ok := s.entries[key]; !ok {
+		return false, nil
+	}
+	if until, ok := s.leases[key]; ok && time.Now().Before(until) {
+		return false, nil
+	}
+	s.leases[key] = time.Now().Add(lease)
+	return true, nil
+}
+
+// Release implements ExpiryStore.
+func (s *MemoryExpiryStore) Release(ctx context.Context, entry ExpiryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.leases, entry.key())
+	return nil
+}
+
+// Remove implements ExpiryStore.
+func (s *MemoryExpiryStore) Remove(ctx context.Context, entry ExpiryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, entry.key())
+	delete(s.leases, entry.key())
+	return nil
+}
+
+// FileExpiryStore is an ExpiryStore that keeps every entry in its own JSON
+// file under a directory, so entries survive restarts and can be shared by
+// several processes. A lease is a file next to the entry, claimed and
+// dropped under a lock on the directory; an expired lease is taken over by
+// the next runner. Leases are owned by the store value that claimed them:
+// runners that should lease entries separately need stores of their own.
+type FileExpiryStore struct {
+	dir string
+
+	mu     sync.Mutex
+	owners map[string]string
+}
+
+// NewFileExpiryStore returns an expiry store writing to dir, creating it if
+// needed.
+func NewFileExpiryStore(dir string) (*FileExpiryStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileExpiryStore{dir: dir, owners: make(map[string]string)}, nil
+}
+
+func (s *FileExpiryStore) entryPath(entry ExpiryEntry) string {
+	return filepath.Join(s.dir, entry.key()+".json")
+}
+
+func (s *FileExpiryStore) leasePath(entry ExpiryEntry) string {
+	return filepath.Join(s.dir, entry.key()+".lease")
+}
+
+// Track implements ExpiryStore.
+func (s *FileExpiryStore) Track(ctx context.Context, entry ExpiryEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.entryPath(entry), data, 0o644)
+}
+
+// Due implements ExpiryStore.
+func (s *FileExpiryStore) Due(ctx context.Context, now time.Time) ([]ExpiryEntry, error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var due []ExpiryEntry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(s.dir, file.Name())
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			// Removed by another runner in the meantime
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		var entry ExpiryEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("invalid expiry entry %s: %w", path, err)
+		}
+		if !entry.ExpiresAt.After(now) {
+			due = append(due, entry)
+		}
+	}
+	sortExpiryEntries(due)
+	return due, nil
+}
+
+type expiryLease struct {
+	Owner string    `json:"owner"`
+	Until time.Time `json:"until"`
+}
+
+// errLeaseLost is returned when a lease expired and was taken over by
+// another runner before its owner was done with the entry.
+var errLeaseLost = errors.New("lease taken over by another runner")
+
+// withLock runs fn under the lock of the store directory, shared by every
+// process using it.
+func (s *FileExpiryStore) withLock(fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return withFileLock(filepath.Join(s.dir, ".lock"), fn)
+}
+
+// readLease returns the lease on an entry, and whether there is one. A
+// lease that cannot be parsed counts as held by someone else.
+func (s *FileExpiryStore) readLease(entry ExpiryEntry) (expiryLease, bool, error) {
+	data, err := os.ReadFile(s.leasePath(entry))
+	if errors.Is(err, fs.ErrNotExist) {
+		return expiryLease{}, false, nil
+	} else if err != nil {
+		return expiryLease{}, false, err
+	}
+	var lease expiryLease
+	if err := json.Unmarshal(data, &lease); err != nil {
+		return expiryLease{Until: time.Now().Add(defaultExpiryLease)}, true, nil
+	}
+	return lease, true, nil
+}
+
+// Claim implements ExpiryStore.
+func (s *FileExpiryStore) Claim(ctx context.Context, entry ExpiryEntry, lease time.Duration) (bool, error) {
+	owner, err := GenerateID()
+	if err != nil {
+		return false, err
+	}
+	data, err := json.Marshal(expiryLease{Owner: owner, Until: time.Now().Add(lease)})
+	if err != nil {
+		return false, err
+	}
+
+	claimed := false
+	err = s.withLock(func() error {
+		// The entry may have been removed by the runner holding the
+		// previous lease
+		if _, err := os.Stat(s.entryPath(entry)); errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		current, held, err := s.readLease(entry)
+		if err != nil || (held && time.Now().Before(current.Until)) {
+			return err
+		}
+
+		// Written whole and renamed into place, so the lease is never
+		// seen half written
+		if err := writeFileAtomic(s.leasePath(entry), data, 0o644); err != nil {
+			return err
+		}
+		s.owners[entry.key()] = owner
+		claimed = true
+		return nil
+	})
+	return claimed, err
+}
+
+// Release implements ExpiryStore. It fails with errLeaseLost if the lease
+// was taken over, leaving the new owner's lease in place.
+func (s *FileExpiryStore) Release(ctx context.Context, entry ExpiryEntry) error {
+	return s.withLock(func() error {
+		return s.dropLease(entry)
+	})
+}
+
+// Remove implements ExpiryStore. An entry leased by another runner is left
+// to that runner, and errLeaseLost returned.
+func (s *FileExpiryStore) Remove(ctx context.Context, entry ExpiryEntry) error {
+	return s.withLock(func() error {
+		if err := s.dropLease(entry); err != nil {
+			return err
+		}
+		if err := os.Remove(s.entryPath(entry)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+}
+
+// dropLease removes the lease on an entry unless another runner holds it.
+// It must be called under the lock.
+func (s *FileExpiryStore) dropLease(entry ExpiryEntry) error {
+	key := entry.key()
+	owner := s.owners[key]
+	delete(s.owners, key)
+
+	current, held, err := s.readLease(entry)
+	if err != nil || !held {
+		return err
+	}
+	if current.Owner != owner {
+		return fmt.Errorf("expiry entry %s/%s: %w", entry.CollectionID, entry.ResourceID, errLeaseLost)
+	}
+	if err := os.Remove(s.leasePath(entry)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func sortExpiryEntries(entries []ExpiryEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ExpiresAt.Before(entries[j].ExpiresAt)
+	})
+}
@@ -0,0 +1,430 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// ArchiveVersion is the version of the archive format written by
+	// ExportCollection.
+	ArchiveVersion = 1
+
+	archiveManifestName = "manifest.json"
+	archiveContentDir   = "content/"
+	exportPageSize      = 100
+)
+
+// ArchiveManifest describes the contents of a collection archive. It is
+// stored as manifest.json at the root of the zip file, next to a content
+// directory holding the files that were uploaded to the collection.
+type ArchiveManifest struct {
+	Version      int               `json:"version"`
+	CollectionID string            `json:"collection_id"`
+	ExportedAt   time.Time         `json:"exported_at"`
+	Resources    []ArchiveResource `json:"resources"`
+}
+
+// ArchiveResource is a resource of an archived collection.
+type ArchiveResource struct {
+
+	// The ID of the resource in the exported collection
+	ResourceID string `json:"resource_id"`
+
+	Type ResourceType `json:"type"`
+
+	// The source of the resource: a URL, or the content of text and JSON
+	// resources
+	Source string `json:"source"`
+
+	// (optional) Path in the archive of the uploaded file content
+	File string `json:"file,omitempty"`
+
+	Size      int64  `json:"size,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+// ArchiveProgress reports the progress of an export or import.
+type ArchiveProgress struct {
+
+	// Resources processed so far, including Resource, out of Total
+	Done  int
+	Total int
+
+	// The resource just processed
+	Resource ArchiveResource
+
+	// The error the resource failed with, nil on success
+	Err error
+}
+
+// ExportOptions configures ExportCollection.
+type ExportOptions struct {
+
+	// Do not download the content of uploaded files; only their URL is kept.
+	SkipContent bool
+
+	// (optional) Called after every resource is written to the archive.
+	OnProgress func(ArchiveProgress)
+}
+
+// ExportCollection writes every resource of a collection to a zip archive
+// at archivePath. Text, JSON, web and YouTube resources are archived by
+// their source; the content of uploaded files is downloaded into the
+// archive so the collection can be recreated without the original upload.
+// The archive is only created once the export succeeds.
+func (c *ragClient) ExportCollection(ctx context.Context, collectionID, archivePath string, opts ExportOptions, callOpts ...CallOption) (ArchiveManifest, error) {
+	ctx, cancel := withCallOptions(ctx, callOpts)
+	defer cancel()
+
+	items, err := c.listAllResources(ctx, collectionID)
+	if err != nil {
+		return ArchiveManifest{}, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(archivePath), "."+filepath.Base(archivePath)+".tmp*")
+	if err != nil {
+		return ArchiveManifest{}, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	manifest := ArchiveManifest{
+		Version:      ArchiveVersion,
+		CollectionID: collectionID,
+		ExportedAt:   time.Now().UTC(),
+		Resources:    make([]ArchiveResource, 0, len(items)),
+	}
+
+	zw := zip.NewWriter(tmp)
+	for i, item := range items {
+		resource := ArchiveResource{
+			ResourceID: item.ResourceID,
+			Type:       item.Type,
+			Source:     item.Source,
+			Size:       item.Size,
+			CreatedAt:  item.CreatedAt,
+		}
+		if item.Type == ResourceTypeFile && isHTTPURL(item.Source) && !opts.SkipContent {
+			resource.File = fmt.Sprintf("%s%04d-%s", archiveContentDir, i+1, archiveFileName(item.Source))
+			if err := c.client.download(ctx, item.Source, zw, resource.File); err != nil {
+				return ArchiveManifest{}, fmt.Errorf("export resource %s: %w", item.ResourceID, err)
+			}
+		}
+		manifest.Resources = append(manifest.Resources, resource)
+		if opts.OnProgress != nil {
+			opts.OnProgress(ArchiveProgress{Done: i + 1, Total: len(items), Resource: resource})
+		}
+	}
+
+	w, err := zw.Create(archiveManifestName)
+	if err != nil {
+		return ArchiveManifest{}, err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return ArchiveManifest{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return ArchiveManifest{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return ArchiveManifest{}, err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return ArchiveManifest{}, err
+	}
+	if err := os.Rename(tmp.Name(), archivePath); err != nil {
+		return ArchiveManifest{}, err
+	}
+	return manifest, nil
+}
+
+// listAllResources pages through ListResources until the last page.
+func (c *ragClient) listAllResources(ctx context.Context, collectionID string) ([]ResourceItem, error) {
+	var items []ResourceItem
+	for page := 1; ; page++ {
+		response, err := c.ListResources(ctx, ListResourcesRequest{
+			CollectionID: collectionID,
+			Page:         page,
+			PageSize:     exportPageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, response.Results...)
+		if response.Next == "" || len(response.Results) == 0 {
+			return items, nil
+		}
+	}
+}
+
+// download copies the content at rawURL into a new file of the archive.
+func (c *apiClient) download(ctx context.Context, rawURL string, zw *zip.Writer, name string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("download %s: %s", rawURL, resp.Status)
+	}
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// archiveFileName returns the file name of a URL, for use inside an archive.
+func archiveFileName(rawURL string) string {
+	name := "file"
+	if u, err := url.Parse(rawURL); err == nil {
+		if base := path.Base(u.Path); base != "/" && base != "." {
+			name = base
+		}
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// ImportOptions configures ImportCollection.
+type ImportOptions struct {
+
+	// (optional) The collection to create, instead of the archived one
+	CollectionID string
+
+	// (optional) File recording the progress of the import. When set, an
+	// interrupted or partially failed import picks up where it stopped
+	// when run again with the same state file. The file is removed once
+	// every resource is imported.
+	StatePath string
+
+	// (optional) Called after every resource is processed.
+	OnProgress func(ArchiveProgress)
+}
+
+// ImportState records the progress of an import so it can be resumed.
+type ImportState struct {
+	CollectionID string `json:"collection_id"`
+
+	// Whether the collection was already created
+	Created bool `json:"created"`
+
+	// IDs of the new resources, by ID of the archived resource
+	Imported map[string]string `json:"imported"`
+}
+
+// ImportFailure is a resource that could not be imported.
+type ImportFailure struct {
+	Resource ArchiveResource
+	Err      error
+}
+
+// ImportResponse summarizes an import.
+type ImportResponse struct {
+	CollectionID string
+
+	// IDs of the new resources, by ID of the archived resource, including
+	// the resources imported by earlier runs
+	ResourceIDs map[string]string
+
+	// Number of resources imported by this run
+	Imported int
+
+	// Number of resources imported by earlier runs
+	Resumed int
+
+	Failed []ImportFailure
+	Tokens int
+}
+
+// ImportCollection recreates a collection from an archive written by
+// ExportCollection. The collection is created with EnsureCollection, so an
+// existing collection is imported into rather than being an error, and
+// every resource is inserted with InsertResource, uploading archived file
+// content again. A failing resource does not stop the import; its error is
+// reported in ImportResponse.Failed.
+func (c *ragClient) ImportCollection(ctx context.Context, archivePath string, opts ImportOptions, callOpts ...CallOption) (ImportResponse, error) {
+	ctx, cancel := withCallOptions(ctx, callOpts)
+	defer cancel()
+
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return ImportResponse{}, err
+	}
+	defer archive.Close()
+
+	manifest, err := readArchiveManifest(&archive.Reader)
+	if err != nil {
+		return ImportResponse{}, fmt.Errorf("%s: %w", archivePath, err)
+	}
+
+	collectionID := opts.CollectionID
+	if collectionID == "" {
+		collectionID = manifest.CollectionID
+	}
+
+	state, err := loadImportState(opts.StatePath, collectionID)
+	if err != nil {
+		return ImportResponse{}, err
+	}
+
+	if !state.Created {
+		if _, err := c.EnsureCollection(ctx, collectionID); err != nil {
+			return ImportResponse{}, fmt.Errorf("ensure collection %s: %w", collectionID, err)
+		}
+		state.Created = true
+		if err := saveImportState(opts.StatePath, state); err != nil {
+			return ImportResponse{}, err
+		}
+	}
+
+	response := ImportResponse{CollectionID: collectionID, ResourceIDs: state.Imported}
+	for i, resource := range manifest.Resources {
+		if err := ctx.Err(); err != nil {
+			return response, err
+		}
+
+		progress := ArchiveProgress{Done: i + 1, Total: len(manifest.Resources), Resource: resource}
+		if _, ok := state.Imported[resource.ResourceID]; ok {
+			response.Resumed++
+		} else {
+			inserted, err := c.importResource(ctx, &archive.Reader, collectionID, resource)
+			if err != nil {
+				progress.Err = err
+				response.Failed = append(response.Failed, ImportFailure{Resource: resource, Err: err})
+			} else {
+				state.Imported[resource.ResourceID] = inserted.ResourceID
+				response.Imported++
+				response.Tokens += inserted.Tokens
+				if err := saveImportState(opts.StatePath, state); err != nil {
+					return response, err
+				}
+			}
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+	}
+
+	if len(response.Failed) == 0 && opts.StatePath != "" {
+		if err := os.Remove(opts.StatePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return response, err
+		}
+	}
+	return response, nil
+}
+
+func (c *ragClient) importResource(ctx context.Context, archive *zip.Reader, collectionID string, resource ArchiveResource) (ResourceInsertResponse, error) {
+	keyOpts := idempotencyOptions(ctx, resource.ResourceID)
+	if resource.File == "" {
+		return c.InsertResource(ctx, collectionID, resource.Source, resource.Type, keyOpts...)
+	}
+
+	file, err := archive.Open(resource.File)
+	if err != nil {
+		return ResourceInsertResponse{}, err
+	}
+	defer file.Close()
+
+	// Drop the ordinal ExportCollection prefixed the file name with
+	name := path.Base(resource.File)
+	if _, rest, ok := strings.Cut(name, "-"); ok {
+		name = rest
+	}
+	fullID, err := c.qualify(collectionID)
+	if err != nil {
+		return ResourceInsertResponse{}, err
+	}
+	resourceURL, err := c.client.upload(ctx, file, fullID, name)
+	if err != nil {
+		return ResourceInsertResponse{}, err
+	}
+	return c.InsertResource(ctx, collectionID, resourceURL, resource.Type, keyOpts...)
+}
+
+func readArchiveManifest(archive *zip.Reader) (ArchiveManifest, error) {
+	var manifest ArchiveManifest
+	file, err := archive.Open(archiveManifestName)
+	if err != nil {
+		return manifest, fmt.Errorf("not a collection archive: %w", err)
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.Version < 1 || manifest.Version > ArchiveVersion {
+		return manifest, fmt.Errorf("unsupported archive version %d", manifest.Version)
+	}
+	return manifest, nil
+}
+
+// loadImportState reads the state of an earlier import into collectionID,
+// or returns a fresh state if there is none.
+func loadImportState(statePath, collectionID string) (*ImportState, error) {
+	state := &ImportState{CollectionID: collectionID, Imported: make(map[string]string)}
+	if statePath == "" {
+		return state, nil
+	}
+
+	data, err := os.ReadFile(statePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid import state %s: %w", statePath, err)
+	}
+	if state.CollectionID != collectionID {
+		return nil, fmt.Errorf("import state %s belongs to collection %s, not %s", statePath, state.CollectionID, collectionID)
+	}
+	if state.Imported == nil {
+		state.Imported = make(map[string]string)
+	}
+	return state, nil
+}
+
+func saveImportState(statePath string, state *ImportState) error {
+	if statePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(statePath, data, 0o644)
+}
@@ -0,0 +1,222 @@
+package wetro
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestExportImportCollection(t *testing.T) {
+	var mu sync.Mutex
+	var created []string
+	collections := map[string]bool{}
+	var inserted []ResourceInsertRequest
+	uploads := map[string]string{}
+	var uploadCollections []string
+	failText := true
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if id, ok := strings.CutPrefix(r.URL.Path, "/v1/collection/get/"); ok {
+			id = strings.Trim(id, "/")
+			json.NewEncoder(w).Encode(GetCollectionResponse{Success: true, Found: collections[id], CollectionID: id})
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/resource/all/":
+			if r.URL.Query().Get("page") == "1" {
+				json.NewEncoder(w).Encode(ListResourcesResponse{
+					Count: 3,
+					Next:  server.URL + "/v1/resource/all/?page=2",
+					Results: []ResourceItem{
+						{ResourceID: "r1", Type: ResourceTypeText, Source: "some text"},
+						{ResourceID: "r2", Type: ResourceTypeFile, Source: server.URL + "/files/guide.pdf", Size: 7},
+					},
+				})
+				return
+			}
+			json.NewEncoder(w).Encode(ListResourcesResponse{
+				Count:   3,
+				Results: []ResourceItem{{ResourceID: "r3", Type: ResourceTypeWeb, Source: "https://example.com"}},
+			})
+		case "/files/guide.pdf":
+			io.WriteString(w, "%PDF-1.")
+		case "/upload/":
+			file, header, _ := r.FormFile("file")
+			data, _ := io.ReadAll(file)
+			uploads[header.Filename] = string(data)
+			uploadCollections = append(uploadCollections, r.FormValue("collection_id"))
+			json.NewEncoder(w).Encode(map[string]string{"url": server.URL + "/uploaded/" + header.Filename})
+		case "/v1/collection/create/":
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			created = append(created, req["collection_id"])
+			collections[req["collection_id"]] = true
+			json.NewEncoder(w).Encode(CollectionCreateResponse{Success: true, CollectionID: req["collection_id"]})
+		case "/v1/resource/insert/":
+			var req ResourceInsertRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Type == ResourceTypeText && failText {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				json.NewEncoder(w).Encode(map[string]string{"error": "unavailable"})
+				return
+			}
+			inserted = append(inserted, req)
+			json.NewEncoder(w).Encode(ResourceInsertResponse{
+				Success:    true,
+				ResourceID: fmt.Sprintf("new-%d", len(inserted)),
+				Tokens:     2,
+			})
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+		c.uploadURL = server.URL + "/upload/"
+	})
+	ctx := context.Background()
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "docs.zip")
+	statePath := filepath.Join(dir, "import-state.json")
+
+	t.Run("Export", func(t *testing.T) {
+		var progress []ArchiveProgress
+		manifest, err := client.RAG.ExportCollection(ctx, "docs", archivePath, ExportOptions{
+			OnProgress: func(p ArchiveProgress) { progress = append(progress, p) },
+		})
+		if err != nil {
+			t.Fatalf("ExportCollection failed: %v", err)
+		}
+		if len(manifest.Resources) != 3 || manifest.Resources[1].File != "content/0002-guide.pdf" {
+			t.Errorf("Unexpected manifest %+v", manifest)
+		}
+		if len(progress) != 3 || progress[2].Done != 3 || progress[2].Total != 3 {
+			t.Errorf("Unexpected progress %+v", progress)
+		}
+
+		archive, err := zip.OpenReader(archivePath)
+		if err != nil {
+			t.Fatalf("Invalid archive: %v", err)
+		}
+		defer archive.Close()
+		file, err := archive.Open("content/0002-guide.pdf")
+		if err != nil {
+			t.Fatalf("Expected the uploaded file in the archive: %v", err)
+		}
+		data, _ := io.ReadAll(file)
+		if string(data) != "%PDF-1." {
+			t.Errorf("Unexpected archived content %q", data)
+		}
+		if info, err := os.Stat(archivePath); err != nil || (runtime.GOOS != "windows" && info.Mode().Perm() != 0o644) {
+			t.Errorf("Expected a 0644 archive, got %v (%v)", info.Mode(), err)
+		}
+	})
+
+	t.Run("ImportPartialFailure", func(t *testing.T) {
+		response, err := client.RAG.ImportCollection(ctx, archivePath, ImportOptions{
+			CollectionID: "docs-copy",
+			StatePath:    statePath,
+		})
+		if err != nil {
+			t.Fatalf("ImportCollection failed: %v", err)
+		}
+		if response.Imported != 2 || len(response.Failed) != 1 || response.Failed[0].Resource.ResourceID != "r1" {
+			t.Errorf("Expected the text resource to fail, got %+v", response)
+		}
+		if uploads["guide.pdf"] != "%PDF-1." {
+			t.Errorf("Expected the archived file to be uploaded again, got %v", uploads)
+		}
+		if inserted[0].Resource != server.URL+"/uploaded/guide.pdf" || inserted[0].Type != ResourceTypeFile {
+			t.Errorf("Unexpected file insert %+v", inserted[0])
+		}
+		if _, err := os.Stat(statePath); err != nil {
+			t.Errorf("Expected the import state to be kept: %v", err)
+		}
+	})
+
+	t.Run("ImportResume", func(t *testing.T) {
+		mu.Lock()
+		failText = false
+		mu.Unlock()
+
+		var progress []ArchiveProgress
+		response, err := client.RAG.ImportCollection(ctx, archivePath, ImportOptions{
+			CollectionID: "docs-copy",
+			StatePath:    statePath,
+			OnProgress:   func(p ArchiveProgress) { progress = append(progress, p) },
+		})
+		if err != nil {
+			t.Fatalf("ImportCollection failed: %v", err)
+		}
+		if response.Imported != 1 || response.Resumed != 2 || len(response.ResourceIDs) != 3 {
+			t.Errorf("Expected only the failed resource to be imported, got %+v", response)
+		}
+		if len(created) != 1 || len(inserted) != 3 || inserted[2].Resource != "some text" {
+			t.Errorf("Expected no duplicate work, got %v and %+v", created, inserted)
+		}
+		if len(progress) != 3 {
+			t.Errorf("Expected progress for every resource, got %d", len(progress))
+		}
+		if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+			t.Error("Expected the import state to be removed")
+		}
+	})
+
+	t.Run("ImportExisting", func(t *testing.T) {
+		mu.Lock()
+		created = nil
+		mu.Unlock()
+
+		response, err := client.RAG.ImportCollection(ctx, archivePath, ImportOptions{CollectionID: "docs-copy"})
+		if err != nil {
+			t.Fatalf("ImportCollection into an existing collection failed: %v", err)
+		}
+		if response.Imported != 3 || len(created) != 0 {
+			t.Errorf("Expected the resources to be imported without creating the collection, got %+v and %v", response, created)
+		}
+	})
+
+	t.Run("ImportNamespaced", func(t *testing.T) {
+		mu.Lock()
+		created, inserted, uploadCollections = nil, nil, nil
+		mu.Unlock()
+
+		if _, err := client.Namespace("tenant").RAG.ImportCollection(ctx, archivePath, ImportOptions{CollectionID: "docs"}); err != nil {
+			t.Fatalf("ImportCollection failed: %v", err)
+		}
+		if len(uploadCollections) != 1 || uploadCollections[0] != "tenant__docs" {
+			t.Errorf("Expected the upload to target tenant__docs, got %v", uploadCollections)
+		}
+		for _, req := range inserted {
+			if req.CollectionID != "tenant__docs" {
+				t.Errorf("Expected inserts into tenant__docs, got %q", req.CollectionID)
+			}
+		}
+	})
+
+	t.Run("InvalidArchive", func(t *testing.T) {
+		path := filepath.Join(dir, "empty.zip")
+		f, _ := os.Create(path)
+		zip.NewWriter(f).Close()
+		f.Close()
+		if _, err := client.RAG.ImportCollection(ctx, path, ImportOptions{}); err == nil {
+			t.Error("Expected an error for an archive without a manifest")
+		}
+	})
+}
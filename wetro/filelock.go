@@ -0,0 +1,27 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"fmt"
+	"os"
+)
+
+// withFileLock runs fn while holding an exclusive lock on the file at path,
+// creating it if needed. The lock is advisory and held across processes,
+// so the file stores use it around their read-modify-write cycles.
+func withFileLock(path string, fn func() error) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := lockFile(file); err != nil {
+		return fmt.Errorf("lock %s: %w", path, err)
+	}
+	defer unlockFile(file)
+	return fn()
+}
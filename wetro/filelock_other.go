@@ -0,0 +1,18 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+//go:build !unix && !windows
+
+package wetro
+
+import "os"
+
+// Platforms without file locks only get the stores' in-process locking.
+func lockFile(file *os.File) error {
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,307 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// IngestOptions controls which files IngestDirectory picks up and how they
+// are inserted.
+type IngestOptions struct {
+
+	// (optional) Glob patterns a file must match to be ingested. Patterns
+	// without a "/" are matched against the file name, other patterns against
+	// the slash-separated path relative to the root. "**" matches any number
+	// of directories. An empty list matches every file.
+	Include []string
+
+	// (optional) Glob patterns for files and directories to leave out,
+	// using the same rules as Include.
+	Exclude []string
+
+	// (optional) Files larger than this many bytes are skipped. Zero means no limit.
+	MaxFileSize int64
+
+	// Ingest files and directories whose name starts with a dot.
+	IncludeHidden bool
+
+	// (optional) Where to write the manifest once ingestion completes.
+	ManifestPath string
+
+	// Options passed on to InsertResources.
+	Bulk BulkInsertOptions
+}
+
+// ManifestEntry records the resource created for a single file.
+type ManifestEntry struct {
+	ResourceID string `json:"resource_id"`
+	Size       int64  `json:"size"`
+	Hash       string `json:"hash"`
+	Tokens     int    `json:"tokens,omitempty"`
+}
+
+// IngestManifest maps the files of an ingested directory, relative to its
+// root and slash-separated, to the resources created for them.
+type IngestManifest struct {
+	CollectionID string                   `json:"collection_id"`
+	Root         string                   `json:"root"`
+	UpdatedAt    time.Time                `json:"updated_at"`
+	Files        map[string]ManifestEntry `json:"files"`
+
+	// (optional) Resources of changed files that SyncDirectory could not
+	// remove, retried on the next sync
+	Stale []StaleResource `json:"stale,omitempty"`
+}
+
+// StaleResource is a resource left behind by a file that has since changed.
+type StaleResource struct {
+	Path       string `json:"path"`
+	ResourceID string `json:"resource_id"`
+}
+
+// LoadManifest reads a manifest written by IngestDirectory or SyncDirectory.
+func LoadManifest(path string) (IngestManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return IngestManifest{}, err
+	}
+	var manifest IngestManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return IngestManifest{}, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]ManifestEntry)
+	}
+	return manifest, nil
+}
+
+// Save writes the manifest to path as indented JSON.
+func (m IngestManifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0o644)
+}
+
+// SkippedFile is a file IngestDirectory found but did not ingest.
+type SkippedFile struct {
+	Path   string
+	Reason string
+}
+
+// IngestFailure is a file whose insertion failed.
+type IngestFailure struct {
+	Path string
+	Err  error
+}
+
+// IngestResponse contains the outcome of ingesting a directory.
+type IngestResponse struct {
+	Manifest IngestManifest
+	Skipped  []SkippedFile
+	Failed   []IngestFailure
+	Summary  BulkInsertSummary
+}
+
+// IngestDirectory walks root and inserts every matching file into the
+// collection as a ResourceTypeFile resource.
+func (c *ragClient) IngestDirectory(ctx context.Context, collectionID, root string, opts IngestOptions, callOpts ...CallOption) (IngestResponse, error) {
+	ctx, cancel := withCallOptions(ctx, callOpts)
+	defer cancel()
+
+	files, skipped, err := collectFiles(root, opts)
+	if err != nil {
+		return IngestResponse{}, err
+	}
+
+	resources := make([]Resource, len(files))
+	for i, file := range files {
+		resources[i] = Resource{Source: file.path, Type: ResourceTypeFile}
+	}
+
+	bulk, err := c.InsertResources(ctx, collectionID, resources, opts.Bulk, idempotencyOptions(ctx, "")...)
+	if err != nil {
+		return IngestResponse{}, err
+	}
+
+	response := IngestResponse{
+		Manifest: IngestManifest{
+			CollectionID: collectionID,
+			Root:         root,
+			UpdatedAt:    time.Now().UTC(),
+			Files:        make(map[string]ManifestEntry),
+		},
+		Skipped: skipped,
+		Summary: bulk.Summary,
+	}
+	for i, result := range bulk.Results {
+		if result.Err != nil {
+			response.Failed = append(response.Failed, IngestFailure{Path: files[i].relPath, Err: result.Err})
+			continue
+		}
+		hash, err := hashFile(files[i].path)
+		if err != nil {
+			return response, err
+		}
+		response.Manifest.Files[files[i].relPath] = ManifestEntry{
+			ResourceID: result.ResourceID,
+			Size:       files[i].size,
+			Hash:       hash,
+			Tokens:     result.Tokens,
+		}
+	}
+
+	if opts.ManifestPath != "" {
+		if err := response.Manifest.Save(opts.ManifestPath); err != nil {
+			return response, err
+		}
+	}
+	return response, nil
+}
+
+type localFile struct {
+	path    string
+	relPath string
+	size    int64
+}
+
+// collectFiles walks root and returns the regular files selected by opts,
+// along with the files that were found but filtered out.
+func collectFiles(root string, opts IngestOptions) ([]localFile, []SkippedFile, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !info.IsDir() {
+		return nil, nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	for _, pattern := range append(append([]string{}, opts.Include...), opts.Exclude...) {
+		if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+			return nil, nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	var files []localFile
+	var skipped []SkippedFile
+
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if !opts.IncludeHidden && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if matchAny(opts.Exclude, rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch {
+		case !d.Type().IsRegular():
+			skipped = append(skipped, SkippedFile{Path: rel, Reason: "not a regular file"})
+		case !opts.IncludeHidden && strings.HasPrefix(d.Name(), "."):
+			skipped = append(skipped, SkippedFile{Path: rel, Reason: "hidden"})
+		case matchAny(opts.Exclude, rel):
+			skipped = append(skipped, SkippedFile{Path: rel, Reason: "excluded"})
+		case len(opts.Include) > 0 && !matchAny(opts.Include, rel):
+			skipped = append(skipped, SkippedFile{Path: rel, Reason: "not included"})
+		default:
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if opts.MaxFileSize > 0 && info.Size() > opts.MaxFileSize {
+				skipped = append(skipped, SkippedFile{Path: rel, Reason: fmt.Sprintf("larger than %d bytes", opts.MaxFileSize)})
+				return nil
+			}
+			files = append(files, localFile{path: p, relPath: rel, size: info.Size()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return files, skipped, nil
+}
+
+// hashFile returns the hex encoded SHA-256 of the file contents.
+func hashFile(p string) (string, error) {
+	file, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob reports whether the slash-separated relative path matches pattern.
+// Patterns without a "/" only look at the last path element.
+func matchGlob(pattern, rel string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
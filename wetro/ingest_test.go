@@ -0,0 +1,206 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newUploadServer returns a test server implementing the upload service and
+// the resource insert endpoint. Uploaded files are served back under /files/
+// and inserted resources get the uploaded file name and the number of times
+// it was inserted as their ID. Removal requests are passed to onRemove.
+func newUploadServer(t *testing.T, onRemove func(ResourceDeleteRequest)) (*httptest.Server, *Client) {
+	t.Helper()
+
+	var mu sync.Mutex
+	inserts := map[string]int{}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/upload/":
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			io.Copy(io.Discard, file)
+			json.NewEncoder(w).Encode(map[string]string{
+				"url": fmt.Sprintf("%s/files/%s", server.URL, header.Filename),
+			})
+		case r.URL.Path == "/v1/resource/insert/":
+			var req ResourceInsertRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if strings.HasSuffix(req.Resource, "reject.txt") {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "unsupported"})
+				return
+			}
+			name := filepath.Base(req.Resource)
+			mu.Lock()
+			inserts[name]++
+			n := inserts[name]
+			mu.Unlock()
+			json.NewEncoder(w).Encode(ResourceInsertResponse{
+				Success:    true,
+				ResourceID: fmt.Sprintf("res-%s-%d", name, n),
+				Tokens:     1,
+			})
+		case r.URL.Path == "/v1/resource/remove/":
+			var req ResourceDeleteRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if onRemove != nil {
+				mu.Lock()
+				onRemove(req)
+				mu.Unlock()
+			}
+			json.NewEncoder(w).Encode(ResourceDeleteResponse{Success: true})
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+		c.uploadURL = server.URL + "/upload/"
+	})
+	return server, client
+}
+
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestIngestDirectory(t *testing.T) {
+	_, client := newUploadServer(t, nil)
+
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"guide.md":             "# Guide",
+		"api/reference.md":     "# Reference",
+		"api/deep/nested.md":   "# Nested",
+		"api/big.md":           strings.Repeat("x", 2048),
+		"api/reject.txt":       "nope",
+		"notes.tmp":            "scratch",
+		".hidden.md":           "secret",
+		".git/config.md":       "git",
+		"vendor/lib/readme.md": "vendored",
+	})
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+
+	resp, err := client.RAG.IngestDirectory(context.Background(), "docs", root, IngestOptions{
+		Include:      []string{"*.md", "api/*.txt"},
+		Exclude:      []string{"vendor/**", "*.tmp"},
+		MaxFileSize:  1024,
+		ManifestPath: manifestPath,
+	})
+	if err != nil {
+		t.Fatalf("IngestDirectory failed: %v", err)
+	}
+
+	want := map[string]string{
+		"guide.md":           "res-guide.md-1",
+		"api/reference.md":   "res-reference.md-1",
+		"api/deep/nested.md": "res-nested.md-1",
+	}
+	if len(resp.Manifest.Files) != len(want) {
+		t.Errorf("Expected %d files in manifest, got %v", len(want), resp.Manifest.Files)
+	}
+	for rel, id := range want {
+		if resp.Manifest.Files[rel].ResourceID != id {
+			t.Errorf("Expected %s to map to %s, got %+v", rel, id, resp.Manifest.Files[rel])
+		}
+	}
+
+	if len(resp.Failed) != 1 || resp.Failed[0].Path != "api/reject.txt" {
+		t.Errorf("Expected api/reject.txt to fail, got %+v", resp.Failed)
+	}
+
+	reasons := map[string]string{}
+	for _, s := range resp.Skipped {
+		reasons[s.Path] = s.Reason
+	}
+	if reasons["notes.tmp"] != "excluded" || reasons[".hidden.md"] != "hidden" || !strings.HasPrefix(reasons["api/big.md"], "larger") {
+		t.Errorf("Unexpected skipped files %+v", resp.Skipped)
+	}
+	if _, ok := reasons["vendor/lib/readme.md"]; ok {
+		t.Error("Expected excluded directory not to be walked")
+	}
+
+	manifest, err := LoadManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+	if manifest.CollectionID != "docs" || manifest.Files["guide.md"].ResourceID != "res-guide.md-1" {
+		t.Errorf("Unexpected manifest %+v", manifest)
+	}
+}
+
+func TestInsertResourceLocalFile(t *testing.T) {
+	_, client := newUploadServer(t, nil)
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"notes.txt": "notes"})
+	path := filepath.Join(dir, "notes.txt")
+
+	// Paths are uploaded when they are files, given or detected
+	for _, resourceType := range []ResourceType{ResourceTypeFile, ResourceTypeAuto} {
+		resp, err := client.RAG.InsertResource(ctx, "docs", path, resourceType)
+		if err != nil || !strings.HasPrefix(resp.ResourceID, "res-notes.txt-") {
+			t.Errorf("Expected %q path to be uploaded, got %+v, %v", resourceType, resp, err)
+		}
+	}
+
+	// Text is sent as it is, even when it names a file: uploads would fail
+	uploadURL := client.RAG.client.uploadURL
+	client.RAG.client.uploadURL = "http://127.0.0.1:0/"
+	for _, text := range []string{"plain words", path} {
+		if _, err := client.RAG.InsertResource(ctx, "docs", text, ResourceTypeText); err != nil {
+			t.Errorf("Expected %q to be sent as text, got %v", text, err)
+		}
+	}
+	client.RAG.client.uploadURL = uploadURL
+	if _, err := client.RAG.InsertResource(ctx, "docs", filepath.Join(dir, "missing.txt"), ResourceTypeFile); err == nil {
+		t.Error("Expected a missing file to fail")
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*.md", "a/b/c.md", true},
+		{"*.md", "a/b/c.txt", false},
+		{"docs/*.md", "docs/a.md", true},
+		{"docs/*.md", "docs/x/a.md", false},
+		{"docs/**/*.md", "docs/a.md", true},
+		{"docs/**/*.md", "docs/x/y/a.md", true},
+		{"**/test", "a/b/test", true},
+		{"vendor/**", "vendor", true},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
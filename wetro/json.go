@@ -0,0 +1,154 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// jsonLinesBatchSize is the number of JSON Lines records handed to
+// InsertResources at a time, so large files are never held in memory.
+const jsonLinesBatchSize = 100
+
+// marshalJSONResource encodes v as compact JSON. json.RawMessage and []byte
+// values are taken to already hold a JSON document.
+func marshalJSONResource(v any) ([]byte, error) {
+	var data []byte
+	switch raw := v.(type) {
+	case json.RawMessage:
+		data = raw
+	case []byte:
+		data = raw
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		data = b
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, fmt.Errorf("invalid JSON document: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// InsertJSON marshals v and inserts it as a single ResourceTypeJSON resource.
+func (c *ragClient) InsertJSON(ctx context.Context, collectionID string, v any, opts ...CallOption) (ResourceInsertResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
+	data, err := marshalJSONResource(v)
+	if err != nil {
+		return ResourceInsertResponse{}, err
+	}
+	return c.InsertResource(ctx, collectionID, string(data), ResourceTypeJSON, idempotencyOptions(ctx, "")...)
+}
+
+// InsertJSONRecords marshals v, which must encode to a JSON array, and
+// inserts every element as its own ResourceTypeJSON resource.
+func (c *ragClient) InsertJSONRecords(ctx context.Context, collectionID string, v any, opts BulkInsertOptions, callOpts ...CallOption) (BulkInsertResponse, error) {
+	ctx, cancel := withCallOptions(ctx, callOpts)
+	defer cancel()
+
+	data, err := marshalJSONResource(v)
+	if err != nil {
+		return BulkInsertResponse{}, err
+	}
+
+	var records []json.RawMessage
+	if err := json.Unmarshal(data, &records); err != nil {
+		return BulkInsertResponse{}, errors.New("value does not encode to a JSON array")
+	}
+
+	resources := make([]Resource, len(records))
+	for i, record := range records {
+		resources[i] = Resource{Source: string(record), Type: ResourceTypeJSON}
+	}
+	return c.InsertResources(ctx, collectionID, resources, opts, idempotencyOptions(ctx, "")...)
+}
+
+// InsertJSONLines streams JSON Lines from r and inserts every record as its
+// own ResourceTypeJSON resource. Blank lines are ignored and lines that are
+// not valid JSON are reported as failed results. The Index of each result is
+// the zero-based line number of its record.
+func (c *ragClient) InsertJSONLines(ctx context.Context, collectionID string, r io.Reader, opts BulkInsertOptions, callOpts ...CallOption) (BulkInsertResponse, error) {
+	ctx, cancel := withCallOptions(ctx, callOpts)
+	defer cancel()
+
+	var response BulkInsertResponse
+	start := time.Now()
+
+	var batch []Resource
+	var lines []int
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		// Batches are told apart by the line their first record is on
+		bulk, err := c.InsertResources(ctx, collectionID, batch, opts, idempotencyOptions(ctx, "l"+strconv.Itoa(lines[0]))...)
+		if err != nil {
+			return err
+		}
+		for i, result := range bulk.Results {
+			result.Index = lines[i]
+			response.add(result)
+		}
+		batch, lines = batch[:0], lines[:0]
+		return nil
+	}
+
+	reader := bufio.NewReader(r)
+	for line := 0; ; line++ {
+		data, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return response, readErr
+		}
+
+		if record := bytes.TrimSpace(data); len(record) > 0 {
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, record); err != nil {
+				response.add(BulkInsertResult{Index: line, Err: fmt.Errorf("line %d: invalid JSON: %w", line+1, err)})
+			} else {
+				batch = append(batch, Resource{Source: buf.String(), Type: ResourceTypeJSON})
+				lines = append(lines, line)
+			}
+		}
+
+		if len(batch) == jsonLinesBatchSize || readErr == io.EOF {
+			if err := flush(); err != nil {
+				return response, err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	response.Summary.Duration = time.Since(start)
+	return response, nil
+}
+
+// add appends a result and updates the summary accordingly.
+func (r *BulkInsertResponse) add(result BulkInsertResult) {
+	r.Results = append(r.Results, result)
+	r.Summary.Total++
+	r.Summary.Tokens += result.Tokens
+	if result.Err != nil {
+		r.Summary.Failed++
+	} else {
+		r.Summary.Succeeded++
+	}
+}
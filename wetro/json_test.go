@@ -0,0 +1,102 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestInsertJSON(t *testing.T) {
+	var mu sync.Mutex
+	var inserted []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/resource/insert/" {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		var req ResourceInsertRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Type != ResourceTypeJSON || !json.Valid([]byte(req.Resource)) {
+			http.Error(w, `{"error": "expected a JSON resource"}`, http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		inserted = append(inserted, req.Resource)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(ResourceInsertResponse{Success: true, ResourceID: "json-resource", Tokens: 4})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+	ctx := context.Background()
+
+	type product struct {
+		Name  string  `json:"name"`
+		Price float64 `json:"price"`
+	}
+
+	t.Run("InsertJSON", func(t *testing.T) {
+		inserted = nil
+		resp, err := client.RAG.InsertJSON(ctx, "products", product{Name: "lamp", Price: 12.5})
+		if err != nil {
+			t.Fatalf("InsertJSON failed: %v", err)
+		}
+		if resp.ResourceID != "json-resource" {
+			t.Errorf("Unexpected response %+v", resp)
+		}
+		if len(inserted) != 1 || inserted[0] != `{"name":"lamp","price":12.5}` {
+			t.Errorf("Unexpected resource %q", inserted)
+		}
+
+		if _, err := client.RAG.InsertJSON(ctx, "products", json.RawMessage(`{"broken":`)); err == nil {
+			t.Error("Expected invalid raw JSON to be rejected")
+		}
+	})
+
+	t.Run("InsertJSONRecords", func(t *testing.T) {
+		inserted = nil
+		resp, err := client.RAG.InsertJSONRecords(ctx, "products", []product{{"lamp", 1}, {"desk", 2}, {"chair", 3}}, BulkInsertOptions{})
+		if err != nil {
+			t.Fatalf("InsertJSONRecords failed: %v", err)
+		}
+		if resp.Summary.Succeeded != 3 || len(inserted) != 3 {
+			t.Errorf("Expected 3 records inserted, got %+v", resp.Summary)
+		}
+
+		if _, err := client.RAG.InsertJSONRecords(ctx, "products", product{}, BulkInsertOptions{}); err == nil {
+			t.Error("Expected a non-array value to be rejected")
+		}
+	})
+
+	t.Run("InsertJSONLines", func(t *testing.T) {
+		inserted = nil
+		var lines []string
+		for i := 0; i < jsonLinesBatchSize+5; i++ {
+			lines = append(lines, `{"n": 1}`)
+		}
+		lines[3] = ""
+		lines[7] = `{"n": `
+		input := strings.Join(lines, "\n")
+
+		resp, err := client.RAG.InsertJSONLines(ctx, "products", strings.NewReader(input), BulkInsertOptions{})
+		if err != nil {
+			t.Fatalf("InsertJSONLines failed: %v", err)
+		}
+		if resp.Summary.Total != jsonLinesBatchSize+4 || resp.Summary.Failed != 1 {
+			t.Errorf("Unexpected summary %+v", resp.Summary)
+		}
+		if failed := resp.Failed(); len(failed) != 1 || failed[0].Index != 7 {
+			t.Errorf("Expected line index 7 to fail, got %+v", failed)
+		}
+		if len(inserted) != jsonLinesBatchSize+3 || inserted[0] != `{"n":1}` {
+			t.Errorf("Expected compacted records to be inserted, got %d", len(inserted))
+		}
+	})
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Role identifies the author of a chat message.
+type Role string
+
+// The known roles. They are untyped so they can be used both as a Role and
+// for the string Role field of MessageObject.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Valid reports whether r is one of the known roles.
+func (r Role) Valid() bool {
+	switch r {
+	case RoleSystem, RoleUser, RoleAssistant:
+		return true
+	}
+	return false
+}
+
+// NewMessage returns a chat history message.
+func NewMessage(role Role, content string) Message {
+	return Message{"role": string(role), "content": content}
+}
+
+// Role returns the role of the message.
+func (m Message) Role() Role {
+	return Role(m["role"])
+}
+
+// Content returns the content of the message.
+func (m Message) Content() string {
+	return m["content"]
+}
+
+// Validate checks that the message only holds a known role and its content.
+func (m Message) Validate() error {
+	var unknown []string
+	for key := range m {
+		if key != "role" && key != "content" {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown message keys %q", unknown)
+	}
+	if _, ok := m["role"]; !ok {
+		return fmt.Errorf("message has no role")
+	}
+	if !m.Role().Valid() {
+		return fmt.Errorf("unknown role %q", m["role"])
+	}
+	return nil
+}
+
+// ToMessageObject converts a chat history message into the form used for
+// text generation.
+func (m Message) ToMessageObject() (MessageObject, error) {
+	if err := m.Validate(); err != nil {
+		return MessageObject{}, err
+	}
+	return MessageObject{Role: string(m.Role()), Content: m.Content()}, nil
+}
+
+// Validate checks that the message has a known role.
+func (m MessageObject) Validate() error {
+	if !Role(m.Role).Valid() {
+		return fmt.Errorf("unknown role %q", m.Role)
+	}
+	return nil
+}
+
+// ToMessage converts a text generation message into a chat history message.
+func (m MessageObject) ToMessage() Message {
+	return NewMessage(Role(m.Role), m.Content)
+}
+
+// ToMessageObjects converts a chat history for use in a TextGenerationRequest.
+func ToMessageObjects(history []Message) ([]MessageObject, error) {
+	objects := make([]MessageObject, len(history))
+	for i, m := range history {
+		object, err := m.ToMessageObject()
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		objects[i] = object
+	}
+	return objects, nil
+}
+
+// ToMessages converts text generation messages into a chat history.
+func ToMessages(objects []MessageObject) []Message {
+	history := make([]Message, len(objects))
+	for i, object := range objects {
+		history[i] = object.ToMessage()
+	}
+	return history
+}
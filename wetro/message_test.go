@@ -0,0 +1,78 @@
+package wetro
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMessageConversion(t *testing.T) {
+	objects := []MessageObject{
+		{Role: RoleSystem, Content: "Be brief."},
+		{Role: RoleUser, Content: "Hi"},
+		{Role: RoleAssistant, Content: "Hello"},
+	}
+
+	history := ToMessages(objects)
+	if history[0]["role"] != "system" || history[2].Content() != "Hello" {
+		t.Errorf("Unexpected history %v", history)
+	}
+
+	// Plain strings, as used before the Role constants existed, still work
+	role := "user"
+	if err := (MessageObject{Role: role, Content: "Hi"}).Validate(); err != nil {
+		t.Errorf("Expected a string role to be accepted, got %v", err)
+	}
+
+	back, err := ToMessageObjects(history)
+	if err != nil {
+		t.Fatalf("ToMessageObjects failed: %v", err)
+	}
+	for i := range objects {
+		if back[i] != objects[i] {
+			t.Errorf("Expected %+v, got %+v", objects[i], back[i])
+		}
+	}
+
+	invalid := []Message{
+		{"role": "robot", "content": "beep"},
+		{"content": "no role"},
+		{"role": "user", "content": "hi", "name": "bob"},
+	}
+	for _, m := range invalid {
+		if _, err := m.ToMessageObject(); err == nil {
+			t.Errorf("Expected %v to be rejected", m)
+		}
+	}
+}
+
+func TestMessageValidation(t *testing.T) {
+	// Requests are validated before anything is sent, so no server is needed.
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = "http://127.0.0.1:0/"
+	})
+	ctx := context.Background()
+
+	_, err := client.RAG.ChatWithCollection(ctx, ChatRequest{
+		CollectionID: "docs",
+		Message:      "hi",
+		ChatHistory:  []Message{NewMessage(RoleUser, "hello"), {"role": "bot", "content": "hey"}},
+	})
+	verr, ok := err.(ValidationError)
+	if !ok {
+		t.Fatalf("Expected ValidationError, got %v", err)
+	}
+	if _, ok := verr.Fields["chat_history[1]"]; !ok {
+		t.Errorf("Expected chat_history[1] to be reported, got %v", verr.Fields)
+	}
+
+	_, err = client.Tools.GenerateText(ctx, TextGenerationRequest{
+		Messages: []MessageObject{{Role: "narrator", Content: "Once upon a time"}},
+	})
+	verr, ok = err.(ValidationError)
+	if !ok {
+		t.Fatalf("Expected ValidationError, got %v", err)
+	}
+	if _, ok := verr.Fields["messages[0]"]; !ok {
+		t.Errorf("Expected messages[0] to be reported, got %v", verr.Fields)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ResponseMeta describes the HTTP response behind a call, see
+// WithResponseMeta. For calls making several requests, it describes the
+// last one to complete.
+type ResponseMeta struct {
+
+	// Status code of the response, zero if no response was received
+	StatusCode int
+
+	Header http.Header
+
+	// Identifier the API assigned to the request, if it sent one
+	RequestID string
+
+	// Time from sending the first attempt to receiving the response,
+	// including retries
+	Latency time.Duration
+
+	// Number of requests sent, including retries
+	Attempts int
+
+	RateLimit RateLimit
+}
+
+// RateLimit holds the rate limit headers of a response. Fields are zero if
+// the response did not include them.
+type RateLimit struct {
+
+	// Requests allowed in the current window
+	Limit int
+
+	// Requests left in the current window
+	Remaining int
+
+	// When the current window ends
+	Reset time.Time
+}
+
+// WithResponseMeta stores the metadata of the response in meta once the
+// call returns, including for error responses.
+func WithResponseMeta(meta *ResponseMeta) CallOption {
+	return func(o *callOptions) {
+		o.responseMeta = meta
+		if o.captureMu == nil {
+			o.captureMu = new(sync.Mutex)
+		}
+	}
+}
+
+// ContextWithResponseMeta returns a context that captures the metadata of
+// the responses of calls made with it into meta, like WithResponseMeta. It
+// is meant for code that calls the client on your behalf.
+func ContextWithResponseMeta(ctx context.Context, meta *ResponseMeta) context.Context {
+	// Without a timeout, the cancel function is a no-op
+	ctx, _ = withCallOptions(ctx, []CallOption{WithResponseMeta(meta)})
+	return ctx
+}
+
+func newResponseMeta(resp *http.Response, latency time.Duration, attempts int) ResponseMeta {
+	meta := ResponseMeta{Latency: latency, Attempts: attempts}
+	if resp == nil {
+		return meta
+	}
+
+	meta.StatusCode = resp.StatusCode
+	meta.Header = resp.Header.Clone()
+	meta.RequestID = firstHeader(resp.Header, "X-Request-Id", "Request-Id")
+	meta.RateLimit = parseRateLimit(resp.Header, time.Now())
+	return meta
+}
+
+// parseRateLimit reads both the X-RateLimit-* headers and the unprefixed
+// RateLimit-* ones. Reset may be given in seconds from now or as a Unix
+// timestamp.
+func parseRateLimit(h http.Header, now time.Time) RateLimit {
+	var limit RateLimit
+	limit.Limit, _ = strconv.Atoi(firstHeader(h, "X-RateLimit-Limit", "RateLimit-Limit"))
+	limit.Remaining, _ = strconv.Atoi(firstHeader(h, "X-RateLimit-Remaining", "RateLimit-Remaining"))
+
+	reset, err := strconv.ParseInt(firstHeader(h, "X-RateLimit-Reset", "RateLimit-Reset"), 10, 64)
+	if err == nil && reset > 0 {
+		// Deltas are far smaller than any timestamp in use
+		if reset > 1_000_000_000 {
+			limit.Reset = time.Unix(reset, 0)
+		} else {
+			limit.Reset = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+	return limit
+}
+
+func firstHeader(h http.Header, keys ...string) string {
+	for _, key := range keys {
+		if v := h.Get(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResponseMeta(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-"+strconv.Itoa(int(requests.Add(1))))
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("X-RateLimit-Reset", "30")
+		if r.URL.Path == "/v1/collection/get/missing/" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"detail": "Not found."})
+			return
+		}
+		if requests.Load() == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(GetCollectionResponse{Success: true, Found: true})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}), func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+	ctx := context.Background()
+
+	t.Run("CallOption", func(t *testing.T) {
+		var meta ResponseMeta
+		if _, err := client.RAG.GetCollection(ctx, "docs", WithResponseMeta(&meta)); err != nil {
+			t.Fatalf("GetCollection failed: %v", err)
+		}
+		if meta.StatusCode != http.StatusOK || meta.Attempts != 2 || meta.RequestID != "req-2" {
+			t.Errorf("Unexpected meta %+v", meta)
+		}
+		if meta.Latency <= 0 {
+			t.Error("Expected the latency to be measured")
+		}
+		if meta.RateLimit.Limit != 100 || meta.RateLimit.Remaining != 42 {
+			t.Errorf("Unexpected rate limit %+v", meta.RateLimit)
+		}
+		if until := time.Until(meta.RateLimit.Reset); until < 25*time.Second || until > 30*time.Second {
+			t.Errorf("Expected the reset in about 30s, got %v", until)
+		}
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		var meta ResponseMeta
+		_, err := client.RAG.GetCollection(ContextWithResponseMeta(ctx, &meta), "missing")
+		if err == nil {
+			t.Fatal("Expected an error")
+		}
+		if meta.StatusCode != http.StatusNotFound || meta.Attempts != 1 || meta.RequestID != "req-3" {
+			t.Errorf("Unexpected meta %+v", meta)
+		}
+	})
+
+	t.Run("ResetTimestamp", func(t *testing.T) {
+		h := http.Header{}
+		h.Set("RateLimit-Reset", "1900000000")
+		limit := parseRateLimit(h, time.Now())
+		if !limit.Reset.Equal(time.Unix(1900000000, 0)) {
+			t.Errorf("Unexpected reset %v", limit.Reset)
+		}
+	})
+}
+
+func TestResponseMetaConcurrent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req")
+		json.NewEncoder(w).Encode(ResourceInsertResponse{Success: true, ResourceID: "r"})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+
+	resources := make([]Resource, 20)
+	for i := range resources {
+		resources[i] = Resource{Source: "text " + strconv.Itoa(i), Type: ResourceTypeText}
+	}
+
+	var meta, ctxMeta ResponseMeta
+	var header http.Header
+	ctx := ContextWithResponseMeta(context.Background(), &ctxMeta)
+	_, err := client.RAG.InsertResources(ctx, "docs", resources, BulkInsertOptions{Concurrency: 8},
+		WithResponseMeta(&meta), WithResponseHeader(&header))
+	if err != nil {
+		t.Fatalf("InsertResources failed: %v", err)
+	}
+	if meta.RequestID != "req" || header.Get("X-Request-Id") != "req" {
+		t.Errorf("Expected the last response to be captured, got %+v and %v", meta, header)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultMultiQueryConcurrency = 4
+
+	defaultSynthesisPrompt = "You merge answers that were retrieved from several knowledge bases into one. " +
+		"Answer the question using only the answers below. Combine what they agree on, " +
+		"point out where they disagree and leave out answers that do not address the question."
+)
+
+// MultiQueryOptions controls how QueryCollections fans out and merges a query.
+type MultiQueryOptions struct {
+
+	// (optional) The model used to query every collection
+	Model ChatModel
+
+	// Maximum number of collections queried at the same time. Defaults to 4.
+	Concurrency int
+
+	// Merge the per-collection answers into a single answer with GenerateText.
+	// Otherwise only the per-collection answers are returned.
+	Synthesize bool
+
+	// (optional) The model used to write the merged answer. Defaults to Model.
+	SynthesisModel ChatModel
+
+	// (optional) Instructions for the merging model, replacing the default.
+	SynthesisPrompt string
+}
+
+// CollectionAnswer is the answer of a single collection to a query.
+type CollectionAnswer struct {
+	CollectionID string
+
+	// The text of the answer, empty on failure
+	Answer string
+
+	// The raw response of QueryCollection
+	Response StandardResponse
+
+	// Tokens consumed by querying this collection
+	Tokens int
+
+	// The error that made the query fail, nil on success
+	Err error
+}
+
+// MultiQueryResponse contains the answers of every queried collection, in
+// the order the collections were given, and the merged answer if requested.
+type MultiQueryResponse struct {
+	Answers []CollectionAnswer
+
+	// The synthesized answer, empty unless MultiQueryOptions.Synthesize is set
+	Answer string
+
+	// Tokens consumed by the synthesis call
+	SynthesisTokens int
+
+	// Tokens consumed in total, queries and synthesis included
+	Tokens int
+}
+
+// Failed returns the answers of the collections that could not be queried.
+func (r MultiQueryResponse) Failed() []CollectionAnswer {
+	var failed []CollectionAnswer
+	for _, answer := range r.Answers {
+		if answer.Err != nil {
+			failed = append(failed, answer)
+		}
+	}
+	return failed
+}
+
+// QueryCollections queries several collections concurrently with the same
+// query. A failing collection does not stop the others; its error is
+// reported in the matching CollectionAnswer. An error is only returned when
+// every collection fails or the synthesis call fails.
+//
+// With opts.Synthesize set, the successful answers are merged into a single
+// answer with GenerateText. When only one collection answers, its answer is
+// used as is.
+func (c *ragClient) QueryCollections(ctx context.Context, collectionIDs []string, query string, opts MultiQueryOptions, callOpts ...CallOption) (MultiQueryResponse, error) {
+	ctx, cancel := withCallOptions(ctx, callOpts)
+	defer cancel()
+
+	v := newValidator()
+	v.check(len(collectionIDs) > 0, "collection_ids", "collection_ids should not be empty")
+	v.check(query != "", "request_query", "request_query should not be empty")
+	for i, id := range collectionIDs {
+		v.check(id != "", fmt.Sprintf("collection_ids[%d]", i), "collection_id should not be empty")
+	}
+	if !v.valid() {
+		return MultiQueryResponse{}, *newValidationError("Validation Error", v.errors)
+	}
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultMultiQueryConcurrency
+	}
+
+	answers := make([]CollectionAnswer, len(collectionIDs))
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i, id := range collectionIDs {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			answers[i] = CollectionAnswer{CollectionID: id}
+			response, err := c.QueryCollection(ctx, QueryRequest{
+				CollectionID: id,
+				Query:        query,
+				Model:        opts.Model,
+			})
+			if err != nil {
+				answers[i].Err = err
+				return
+			}
+			answers[i].Response = response
+			answers[i].Tokens = response.Tokens
+			answers[i].Answer = responseText(response.Response)
+		}(i, id)
+	}
+	wg.Wait()
+
+	response := MultiQueryResponse{Answers: answers}
+	var errs []error
+	var succeeded []CollectionAnswer
+	for _, answer := range answers {
+		response.Tokens += answer.Tokens
+		if answer.Err != nil {
+			errs = append(errs, fmt.Errorf("collection %s: %w", answer.CollectionID, answer.Err))
+		} else {
+			succeeded = append(succeeded, answer)
+		}
+	}
+	if len(succeeded) == 0 {
+		return response, errors.Join(errs...)
+	}
+
+	if !opts.Synthesize {
+		return response, nil
+	}
+	if len(succeeded) == 1 {
+		response.Answer = succeeded[0].Answer
+		return response, nil
+	}
+
+	synthesis, err := c.synthesize(ctx, query, succeeded, opts)
+	if err != nil {
+		return response, fmt.Errorf("synthesize answers: %w", err)
+	}
+	response.Answer = strings.TrimSpace(responseText(synthesis.Response))
+	response.SynthesisTokens = synthesis.Tokens
+	response.Tokens += synthesis.Tokens
+	return response, nil
+}
+
+func (c *ragClient) synthesize(ctx context.Context, query string, answers []CollectionAnswer, opts MultiQueryOptions) (StandardResponse, error) {
+	prompt := opts.SynthesisPrompt
+	if prompt == "" {
+		prompt = defaultSynthesisPrompt
+	}
+	model := opts.SynthesisModel
+	if model == "" {
+		model = opts.Model
+	}
+
+	var content strings.Builder
+	fmt.Fprintf(&content, "Question: %s\n\n", query)
+	for _, answer := range answers {
+		fmt.Fprintf(&content, "Answer from %s:\n%s\n\n", answer.CollectionID, answer.Answer)
+	}
+
+	tools := &toolsClient{client: c.client}
+	return tools.GenerateText(ctx, TextGenerationRequest{
+		Messages: []MessageObject{
+			{Role: RoleSystem, Content: prompt},
+			{Role: RoleUser, Content: content.String()},
+		},
+		Model: model,
+	})
+}
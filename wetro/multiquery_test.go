@@ -0,0 +1,101 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestQueryCollections(t *testing.T) {
+	var synthesisInput atomic.Value
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/collection/query/":
+			var req QueryRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.CollectionID == "broken" {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{"error": "boom"})
+				return
+			}
+			json.NewEncoder(w).Encode(StandardResponse{
+				Success:  true,
+				Tokens:   len(req.CollectionID),
+				Response: map[string]string{"response": req.CollectionID + " says " + req.Query},
+			})
+		case "/v1/text-generation/":
+			var req TextGenerationRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			synthesisInput.Store(req.Messages[len(req.Messages)-1].Content)
+			json.NewEncoder(w).Encode(StandardResponse{Success: true, Tokens: 100, Response: " merged "})
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+	ctx := context.Background()
+
+	t.Run("Answers", func(t *testing.T) {
+		response, err := client.RAG.QueryCollections(ctx, []string{"shoes", "broken", "hats"}, "price?", MultiQueryOptions{})
+		if err != nil {
+			t.Fatalf("QueryCollections failed: %v", err)
+		}
+		if len(response.Answers) != 3 || response.Answers[0].Answer != "shoes says price?" || response.Answers[2].Tokens != 4 {
+			t.Errorf("Unexpected answers %+v", response.Answers)
+		}
+		failed := response.Failed()
+		if len(failed) != 1 || failed[0].CollectionID != "broken" {
+			t.Errorf("Expected the broken collection to fail, got %+v", failed)
+		}
+		if response.Tokens != 9 || response.Answer != "" {
+			t.Errorf("Expected 9 tokens and no merged answer, got %d and %q", response.Tokens, response.Answer)
+		}
+	})
+
+	t.Run("Synthesize", func(t *testing.T) {
+		response, err := client.RAG.QueryCollections(ctx, []string{"shoes", "hats", "broken"}, "price?", MultiQueryOptions{Synthesize: true})
+		if err != nil {
+			t.Fatalf("QueryCollections failed: %v", err)
+		}
+		if response.Answer != "merged" || response.SynthesisTokens != 100 || response.Tokens != 109 {
+			t.Errorf("Unexpected merged response %+v", response)
+		}
+		input, _ := synthesisInput.Load().(string)
+		if !strings.Contains(input, "Answer from shoes:") || strings.Contains(input, "broken") {
+			t.Errorf("Unexpected synthesis input %q", input)
+		}
+	})
+
+	t.Run("SingleAnswer", func(t *testing.T) {
+		response, err := client.RAG.QueryCollections(ctx, []string{"shoes", "broken"}, "price?", MultiQueryOptions{Synthesize: true})
+		if err != nil {
+			t.Fatalf("QueryCollections failed: %v", err)
+		}
+		if response.Answer != "shoes says price?" || response.SynthesisTokens != 0 {
+			t.Errorf("Expected the only answer to be used as is, got %+v", response)
+		}
+	})
+
+	t.Run("AllFailed", func(t *testing.T) {
+		_, err := client.RAG.QueryCollections(ctx, []string{"broken"}, "price?", MultiQueryOptions{})
+		if err == nil || !strings.Contains(err.Error(), "collection broken") {
+			t.Errorf("Expected an error naming the collection, got %v", err)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		_, err := client.RAG.QueryCollections(ctx, nil, "", MultiQueryOptions{})
+		if _, ok := err.(ValidationError); !ok {
+			t.Errorf("Expected ValidationError, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NamespaceSeparator separates a namespace from the collection IDs in it.
+const NamespaceSeparator = "__"
+
+// Namespace returns a view of the client whose RAG calls are confined to the
+// collections of a namespace, such as a tenant. Every collection ID passed
+// to the view is prefixed with the namespace and the separator, and the
+// prefix is stripped from the IDs it returns; collections outside the
+// namespace cannot be reached through the view. Namespaces can be nested.
+//
+// Neither namespace names nor the collection IDs used in a view may start
+// or end with "_" or contain NamespaceSeparator, so that every full ID
+// splits into namespace and collection in a single way. Namespace panics
+// on an invalid name; methods of the view return a ValidationError for an
+// invalid collection ID.
+func (c *Client) Namespace(name string) *Client {
+	if !validNamespacePart(name) {
+		panic(fmt.Sprintf("wetro: invalid namespace %q", name))
+	}
+	rag := *c.RAG
+	rag.namespace = c.RAG.namespace + name + NamespaceSeparator
+	return &Client{RAG: &rag, Tools: c.Tools, api: c.api}
+}
+
+// Namespace returns the prefix of the collection IDs of this view, empty
+// outside a namespace.
+func (c *ragClient) Namespace() string {
+	return c.namespace
+}
+
+// qualify returns the full ID of a collection of the namespace. Outside a
+// namespace, IDs are returned unchanged.
+func (c *ragClient) qualify(id string) (string, error) {
+	if id == "" || c.namespace == "" {
+		return id, nil
+	}
+	if !validNamespacePart(id) {
+		return "", *newValidationError("Validation Error", errorFields{
+			"collection_id": fmt.Sprintf("collection_id in a namespace should not start or end with \"_\" or contain %q", NamespaceSeparator),
+		})
+	}
+	return c.namespace + id, nil
+}
+
+// validNamespacePart reports whether s can be joined to other namespace
+// parts without ambiguity.
+func validNamespacePart(s string) bool {
+	return s != "" &&
+		!strings.HasPrefix(s, "_") &&
+		!strings.HasSuffix(s, "_") &&
+		!strings.Contains(s, NamespaceSeparator)
+}
+
+// strip returns the ID of a collection within the namespace.
+func (c *ragClient) strip(id string) string {
+	return strings.TrimPrefix(id, c.namespace)
+}
+
+// root returns a view of the client outside any namespace.
+func (c *ragClient) root() *ragClient {
+	if c.namespace == "" {
+		return c
+	}
+	root := *c
+	root.namespace = ""
+	return &root
+}
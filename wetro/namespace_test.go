@@ -0,0 +1,170 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNamespace(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string]string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		id, _ := body["collection_id"].(string)
+		if q := r.URL.Query().Get("collection_id"); q != "" {
+			id = q
+		}
+
+		switch {
+		case r.URL.Path == "/v1/collection/create/":
+			seen["create"] = id
+			json.NewEncoder(w).Encode(CollectionCreateResponse{Success: true, CollectionID: id})
+		case strings.HasPrefix(r.URL.Path, "/v1/collection/get/"):
+			id = strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/collection/get/"), "/")
+			seen["get"] = id
+			json.NewEncoder(w).Encode(GetCollectionResponse{Success: true, Found: true, CollectionID: id})
+		case r.URL.Path == "/v1/collection/all/":
+			json.NewEncoder(w).Encode(ListCollectionResponse{
+				Count: 3,
+				Results: []CollectionItem{
+					{CollectionID: "tenant-a__docs"},
+					{CollectionID: "tenant-b__docs"},
+					{CollectionID: "tenant-a__team__notes"},
+				},
+			})
+		case r.URL.Path == "/v1/collection/query/":
+			seen["query"] = id
+			json.NewEncoder(w).Encode(StandardResponse{Success: true})
+		case r.URL.Path == "/v1/collection/chat/":
+			seen["chat"] = id
+			json.NewEncoder(w).Encode(StandardResponse{Success: true})
+		case r.URL.Path == "/v1/resource/insert/":
+			seen["insert"] = id
+			json.NewEncoder(w).Encode(ResourceInsertResponse{Success: true, ResourceID: "r1"})
+		case r.URL.Path == "/v1/resource/all/":
+			seen["list-resources"] = id
+			json.NewEncoder(w).Encode(ListResourcesResponse{})
+		case r.URL.Path == "/v1/resource/remove/":
+			seen["remove"] = id
+			json.NewEncoder(w).Encode(ResourceDeleteResponse{Success: true})
+		case r.URL.Path == "/v1/collection/delete/":
+			seen["delete"] = id
+			json.NewEncoder(w).Encode(DeleteCollectionResponse{Success: true})
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+	tenant := client.Namespace("tenant-a")
+	ctx := context.Background()
+
+	created, err := tenant.RAG.CreateCollection(ctx, "docs")
+	if err != nil || created.CollectionID != "docs" {
+		t.Errorf("Expected the prefix to be stripped, got %+v, %v", created, err)
+	}
+	collection, _ := tenant.RAG.GetCollection(ctx, "docs")
+	if collection.CollectionID != "docs" {
+		t.Errorf("Expected the prefix to be stripped, got %q", collection.CollectionID)
+	}
+	tenant.RAG.QueryCollection(ctx, QueryRequest{CollectionID: "docs", Query: "q"})
+	tenant.RAG.ChatWithCollection(ctx, ChatRequest{CollectionID: "docs", Message: "m"})
+	tenant.RAG.InsertResource(ctx, "docs", "text", ResourceTypeText)
+	tenant.RAG.ListResources(ctx, ListResourcesRequest{CollectionID: "docs"})
+	tenant.RAG.RemoveResource(ctx, ResourceDeleteRequest{CollectionID: "docs", ResourceID: "r1"})
+	tenant.RAG.DeleteCollection(ctx, "docs")
+
+	for _, call := range []string{"create", "get", "query", "chat", "insert", "list-resources", "remove", "delete"} {
+		if seen[call] != "tenant-a__docs" {
+			t.Errorf("Expected %s to target tenant-a__docs, got %q", call, seen[call])
+		}
+	}
+
+	list, err := tenant.RAG.ListCollections(ctx)
+	if err != nil {
+		t.Fatalf("ListCollections failed: %v", err)
+	}
+	if len(list.Results) != 1 || list.Results[0].CollectionID != "docs" {
+		t.Errorf("Expected only the tenant's own collections, got %+v", list.Results)
+	}
+
+	nested := tenant.Namespace("team")
+	if nested.RAG.Namespace() != "tenant-a__team__" {
+		t.Errorf("Unexpected nested namespace %q", nested.RAG.Namespace())
+	}
+	list, _ = nested.RAG.ListCollections(ctx)
+	if len(list.Results) != 1 || list.Results[0].CollectionID != "notes" {
+		t.Errorf("Expected only the nested namespace's collections, got %+v", list.Results)
+	}
+
+	if all, _ := client.RAG.ListCollections(ctx); len(all.Results) != 3 {
+		t.Error("Expected the root client to be left unchanged")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Namespace to panic on an invalid name")
+		}
+	}()
+	client.Namespace("a__b")
+}
+
+func TestNamespaceCollisions(t *testing.T) {
+	client := NewClient("test-api-key")
+
+	for _, name := range []string{"", "_a", "a_", "a__b"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected Namespace(%q) to panic", name)
+				}
+			}()
+			client.Namespace(name)
+		}()
+	}
+
+	tenant := client.Namespace("a")
+	for _, id := range []string{"_secret", "secret_", "b__secret"} {
+		if _, err := tenant.RAG.qualify(id); err == nil {
+			t.Errorf("Expected collection ID %q to be rejected", id)
+		}
+	}
+	_, err := tenant.RAG.GetCollection(context.Background(), "_secret")
+	if _, ok := err.(ValidationError); !ok {
+		t.Errorf("Expected a ValidationError, got %v", err)
+	}
+
+	// Distinct (namespace, collection) pairs never share a full ID
+	seen := map[string]string{}
+	for _, name := range []string{"a", "a-b", "b"} {
+		view := client.Namespace(name)
+		for _, id := range []string{"secret", "b-secret", "x_y"} {
+			full, err := view.RAG.qualify(id)
+			if err != nil {
+				t.Fatalf("qualify(%q) failed: %v", id, err)
+			}
+			if other, ok := seen[full]; ok {
+				t.Errorf("%s/%s collides with %s as %q", name, id, other, full)
+			}
+			seen[full] = name + "/" + id
+		}
+	}
+
+	// IDs outside a namespace are unchanged
+	if full, err := client.RAG.qualify("_legacy"); err != nil || full != "_legacy" {
+		t.Errorf("Expected root IDs to be left unchanged, got %q, %v", full, err)
+	}
+}
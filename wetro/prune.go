@@ -0,0 +1,283 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultPruneConcurrency = 4
+
+// createdAtLayouts are the timestamp layouts CollectionItem.CreatedAt is
+// parsed with.
+var createdAtLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// PruneOptions selects the collections PruneCollections deletes. At least one
+// of Prefix, Pattern or MinAge is required; a collection must match all of
+// those that are set.
+type PruneOptions struct {
+
+	// (optional) Only collections whose ID starts with this prefix
+	Prefix string
+
+	// (optional) Only collections whose ID matches this expression
+	Pattern *regexp.Regexp
+
+	// (optional) Only collections created at least this long ago. Collections
+	// without a readable creation time are kept.
+	MinAge time.Duration
+
+	// Only compute the plan; nothing is deleted.
+	DryRun bool
+
+	// Maximum number of deletions running at the same time. Defaults to 4.
+	Concurrency int
+
+	// (optional) Called with the plan before anything is deleted. Returning
+	// false aborts the prune.
+	Confirm func(PrunePlan) bool
+
+	// (optional) Where the plan is printed before deleting, or instead of
+	// deleting on a dry run.
+	Output io.Writer
+}
+
+// PruneCandidate is a collection selected for deletion.
+type PruneCandidate struct {
+	CollectionID string
+
+	// Zero if the API reported no readable creation time
+	CreatedAt time.Time
+
+	// Aliases pointing at the collection; a protected collection is kept
+	Aliases []string
+}
+
+// PrunePlan lists the collections a prune deletes.
+type PrunePlan struct {
+
+	// Number of collections listed
+	Scanned int
+
+	Collections []PruneCandidate
+
+	// Collections that match but are kept because an alias points at them
+	Protected []PruneCandidate
+}
+
+// String formats the plan for display, one collection per line.
+func (p PrunePlan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d collections to delete\n", len(p.Collections), p.Scanned)
+	now := time.Now()
+	for _, c := range p.Collections {
+		if c.CreatedAt.IsZero() {
+			fmt.Fprintf(&b, "  %s\n", c.CollectionID)
+			continue
+		}
+		fmt.Fprintf(&b, "  %s (created %s, %s ago)\n", c.CollectionID,
+			c.CreatedAt.Format(time.RFC3339), now.Sub(c.CreatedAt).Round(time.Minute))
+	}
+	if len(p.Protected) > 0 {
+		fmt.Fprintf(&b, "%d kept because aliases point at them\n", len(p.Protected))
+		for _, c := range p.Protected {
+			fmt.Fprintf(&b, "  %s (alias %s)\n", c.CollectionID, strings.Join(c.Aliases, ", "))
+		}
+	}
+	return b.String()
+}
+
+// PruneFailure is a collection that could not be deleted.
+type PruneFailure struct {
+	CollectionID string
+	Err          error
+}
+
+// PruneResponse contains the outcome of PruneCollections.
+type PruneResponse struct {
+	Plan PrunePlan
+
+	// Whether the confirmation hook declined the plan
+	Aborted bool
+
+	Deleted []string
+	Failed  []PruneFailure
+}
+
+// PruneCollections deletes the collections matching opts. It pages through
+// every collection, builds a plan of those to delete, prints it to
+// opts.Output and asks opts.Confirm before deleting them. A failing
+// deletion does not stop the others; its error is reported in
+// PruneResponse.Failed.
+//
+// Collections an alias points at are never deleted; they are listed in
+// PrunePlan.Protected instead. This requires an alias store that
+// implements AliasLister, as MemoryAliasStore and FileAliasStore do.
+func (c *ragClient) PruneCollections(ctx context.Context, opts PruneOptions, callOpts ...CallOption) (PruneResponse, error) {
+	ctx, cancel := withCallOptions(ctx, callOpts)
+	defer cancel()
+
+	v := newValidator()
+	v.check(opts.Prefix != "" || opts.Pattern != nil || opts.MinAge > 0, "filters", "at least one of prefix, pattern or min_age is required")
+	v.check(opts.MinAge >= 0, "min_age", "min_age should not be negative")
+	if !v.valid() {
+		return PruneResponse{}, *newValidationError("Validation Error", v.errors)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultPruneConcurrency
+	}
+
+	plan, err := c.planPrune(ctx, opts, time.Now())
+	if err != nil {
+		return PruneResponse{}, err
+	}
+	response := PruneResponse{Plan: plan}
+
+	if opts.Output != nil {
+		if opts.DryRun {
+			fmt.Fprint(opts.Output, "Dry run: ")
+		}
+		fmt.Fprint(opts.Output, plan)
+	}
+	if opts.DryRun || len(plan.Collections) == 0 {
+		return response, nil
+	}
+	if opts.Confirm != nil && !opts.Confirm(plan) {
+		response.Aborted = true
+		return response, nil
+	}
+
+	errs := make([]error, len(plan.Collections))
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i, candidate := range plan.Collections {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			_, errs[i] = c.DeleteCollection(ctx, id)
+		}(i, candidate.CollectionID)
+	}
+	wg.Wait()
+
+	for i, candidate := range plan.Collections {
+		if errs[i] != nil {
+			response.Failed = append(response.Failed, PruneFailure{CollectionID: candidate.CollectionID, Err: errs[i]})
+		} else {
+			response.Deleted = append(response.Deleted, candidate.CollectionID)
+		}
+	}
+	return response, nil
+}
+
+func (c *ragClient) planPrune(ctx context.Context, opts PruneOptions, now time.Time) (PrunePlan, error) {
+	aliased, err := c.aliasTargets(ctx)
+	if err != nil {
+		return PrunePlan{}, err
+	}
+
+	var plan PrunePlan
+	for page := 1; ; {
+		response, err := c.listCollectionsPage(ctx, page)
+		if err != nil {
+			return PrunePlan{}, err
+		}
+		plan.Scanned += len(response.Results)
+
+		for _, item := range response.Results {
+			candidate, ok := matchPrune(item, opts, now)
+			if !ok {
+				continue
+			}
+			// Aliases are stored with full IDs
+			if aliases := aliased[c.namespace+item.CollectionID]; len(aliases) > 0 {
+				candidate.Aliases = aliases
+				plan.Protected = append(plan.Protected, candidate)
+				continue
+			}
+			plan.Collections = append(plan.Collections, candidate)
+		}
+
+		if response.Next == "" || len(response.Results) == 0 {
+			return plan, nil
+		}
+		next := nextPage(response.Next)
+		if next <= page {
+			next = page + 1
+		}
+		page = next
+	}
+}
+
+// aliasTargets returns the aliases of the configured alias store by the
+// full ID of the collection they point at.
+func (c *ragClient) aliasTargets(ctx context.Context) (map[string][]string, error) {
+	lister, ok := c.client.aliases.(AliasLister)
+	if !ok {
+		return nil, nil
+	}
+	aliases, err := lister.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list aliases: %w", err)
+	}
+	targets := make(map[string][]string)
+	for alias, collectionID := range aliases {
+		targets[collectionID] = append(targets[collectionID], c.strip(alias))
+	}
+	for _, names := range targets {
+		sort.Strings(names)
+	}
+	return targets, nil
+}
+
+func matchPrune(item CollectionItem, opts PruneOptions, now time.Time) (PruneCandidate, bool) {
+	candidate := PruneCandidate{CollectionID: item.CollectionID, CreatedAt: parseCreatedAt(item.CreatedAt)}
+	if opts.Prefix != "" && !strings.HasPrefix(item.CollectionID, opts.Prefix) {
+		return candidate, false
+	}
+	if opts.Pattern != nil && !opts.Pattern.MatchString(item.CollectionID) {
+		return candidate, false
+	}
+	if opts.MinAge > 0 && (candidate.CreatedAt.IsZero() || now.Sub(candidate.CreatedAt) < opts.MinAge) {
+		return candidate, false
+	}
+	return candidate, true
+}
+
+func parseCreatedAt(s string) time.Time {
+	for _, layout := range createdAtLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// nextPage returns the page number of a pagination URL, or zero if it has
+// none.
+func nextPage(next string) int {
+	u, err := url.Parse(next)
+	if err != nil {
+		return 0
+	}
+	page, _ := strconv.Atoi(u.Query().Get("page"))
+	return page
+}
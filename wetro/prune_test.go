@@ -0,0 +1,140 @@
+package wetro
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPruneCollections(t *testing.T) {
+	old := time.Now().Add(-72 * time.Hour).UTC().Format("2006-01-02T15:04:05.000000Z")
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	pages := [][]CollectionItem{
+		{{CollectionID: "test-1", CreatedAt: old}, {CollectionID: "prod-docs", CreatedAt: old}},
+		{{CollectionID: "test-2", CreatedAt: recent}, {CollectionID: "test-3", CreatedAt: old}},
+		{{CollectionID: "test-fail", CreatedAt: old}, {CollectionID: "tmp-42", CreatedAt: "garbage"}},
+	}
+
+	var mu sync.Mutex
+	var deleted []string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/collection/all/":
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			if page < 1 {
+				page = 1
+			}
+			response := ListCollectionResponse{Count: 6, Results: pages[page-1]}
+			if page < len(pages) {
+				response.Next = server.URL + "/v1/collection/all/?page=" + strconv.Itoa(page+1)
+			}
+			json.NewEncoder(w).Encode(response)
+		case "/v1/collection/delete/":
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			if req["collection_id"] == "test-fail" {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{"error": "boom"})
+				return
+			}
+			mu.Lock()
+			deleted = append(deleted, req["collection_id"])
+			mu.Unlock()
+			json.NewEncoder(w).Encode(DeleteCollectionResponse{Success: true})
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+	ctx := context.Background()
+
+	t.Run("DryRun", func(t *testing.T) {
+		var out bytes.Buffer
+		response, err := client.RAG.PruneCollections(ctx, PruneOptions{Prefix: "test-", MinAge: 24 * time.Hour, DryRun: true, Output: &out})
+		if err != nil {
+			t.Fatalf("PruneCollections failed: %v", err)
+		}
+		if response.Plan.Scanned != 6 || len(response.Plan.Collections) != 3 {
+			t.Errorf("Expected 3 of 6 collections to match, got %+v", response.Plan)
+		}
+		if len(deleted) != 0 {
+			t.Errorf("Expected a dry run not to delete, got %v", deleted)
+		}
+		if !strings.HasPrefix(out.String(), "Dry run: 3 of 6 collections to delete") || !strings.Contains(out.String(), "test-3 (created ") {
+			t.Errorf("Unexpected plan output %q", out.String())
+		}
+	})
+
+	t.Run("Aborted", func(t *testing.T) {
+		response, err := client.RAG.PruneCollections(ctx, PruneOptions{Prefix: "test-", Confirm: func(PrunePlan) bool { return false }})
+		if err != nil || !response.Aborted || len(deleted) != 0 {
+			t.Errorf("Expected the prune to be aborted, got %+v, %v", response, err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		var confirmed PrunePlan
+		response, err := client.RAG.PruneCollections(ctx, PruneOptions{
+			Pattern:     regexp.MustCompile(`^test-\d$|^test-fail$`),
+			MinAge:      24 * time.Hour,
+			Concurrency: 2,
+			Confirm:     func(p PrunePlan) bool { confirmed = p; return true },
+		})
+		if err != nil {
+			t.Fatalf("PruneCollections failed: %v", err)
+		}
+		if len(confirmed.Collections) != 3 {
+			t.Errorf("Expected the plan to be confirmed, got %+v", confirmed)
+		}
+		sort.Strings(deleted)
+		if len(deleted) != 2 || deleted[0] != "test-1" || deleted[1] != "test-3" {
+			t.Errorf("Unexpected deletions %v", deleted)
+		}
+		if len(response.Failed) != 1 || response.Failed[0].CollectionID != "test-fail" {
+			t.Errorf("Expected test-fail to fail, got %+v", response.Failed)
+		}
+	})
+
+	t.Run("Aliased", func(t *testing.T) {
+		aliases := NewMemoryAliasStore()
+		aliases.Set(ctx, "current", "test-1")
+		aliases.Set(ctx, "tenant__docs", "tenant__test-3")
+		aliased := NewClient("test-api-key", func(c *apiClient) {
+			c.baseURL = server.URL + "/"
+		}, WithAliasStore(aliases))
+
+		var out bytes.Buffer
+		response, err := aliased.RAG.PruneCollections(ctx, PruneOptions{Prefix: "test-", MinAge: 24 * time.Hour, DryRun: true, Output: &out})
+		if err != nil {
+			t.Fatalf("PruneCollections failed: %v", err)
+		}
+		plan := response.Plan
+		if len(plan.Collections) != 2 || len(plan.Protected) != 1 || plan.Protected[0].CollectionID != "test-1" || plan.Protected[0].Aliases[0] != "current" {
+			t.Errorf("Expected test-1 to be protected by its alias, got %+v", plan)
+		}
+		if !strings.Contains(out.String(), "test-1 (alias current)") {
+			t.Errorf("Expected the protected collection in the plan, got %q", out.String())
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		if _, err := client.RAG.PruneCollections(ctx, PruneOptions{}); err == nil {
+			t.Error("Expected an error without any filter")
+		}
+	})
+}
@@ -7,9 +7,11 @@ package wetro
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -17,45 +19,101 @@ import (
 // It handles collection management and querying.
 type ragClient struct {
 	client *apiClient
+
+	// (optional) Prefix of every collection ID, see Client.Namespace
+	namespace string
+
+	// ensuring deduplicates concurrent EnsureCollection calls, across the
+	// namespaces of a client
+	ensuring *ensureGroup
 }
 
-func (c *ragClient) CreateCollection(ctx context.Context, id string) (CollectionCreateResponse, error) {
+// CreateCollection creates a collection. It returns a *CollectionExistsError
+// if a collection with the same ID already exists.
+func (c *ragClient) CreateCollection(ctx context.Context, id string, opts ...CallOption) (CollectionCreateResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
 
+	fullID, err := c.qualify(id)
+	if err != nil {
+		return CollectionCreateResponse{}, err
+	}
 	requestData := map[string]string{
-		"collection_id": id,
+		"collection_id": fullID,
 	}
 	response := CollectionCreateResponse{}
 
-	err := c.client.doRequest(ctx, http.MethodPost, "/collection/create/", nil, requestData, &response)
+	err = c.client.doRequest(ctx, http.MethodPost, "/collection/create/", nil, requestData, &response)
 	if err != nil {
-		return CollectionCreateResponse{}, err
+		return CollectionCreateResponse{}, asCollectionExists(id, err)
 	}
+	response.CollectionID = c.strip(response.CollectionID)
 
 	return response, nil
 }
 
 // GetCollection retrieves a collection
-func (c *ragClient) GetCollection(ctx context.Context, collectionID string) (GetCollectionResponse, error) {
+func (c *ragClient) GetCollection(ctx context.Context, collectionID string, opts ...CallOption) (GetCollectionResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
 	var response GetCollectionResponse
-	err := c.client.doRequest(ctx, http.MethodGet, fmt.Sprintf("/collection/get/%s/", collectionID), nil, nil, &response)
+	fullID, err := c.qualify(collectionID)
 	if err != nil {
 		return GetCollectionResponse{}, err
 	}
+	err = c.client.doRequest(ctx, http.MethodGet, fmt.Sprintf("/collection/get/%s/", fullID), nil, nil, &response)
+	if err != nil {
+		return GetCollectionResponse{}, err
+	}
+	response.CollectionID = c.strip(response.CollectionID)
 	return response, nil
 }
 
-// ListCollections lists all collections
-func (c *ragClient) ListCollections(ctx context.Context) (ListCollectionResponse, error) {
+// ListCollections lists all collections. In a namespace, only the
+// collections of the namespace are returned, not those of nested
+// namespaces; Count and the pagination links still refer to every
+// collection.
+func (c *ragClient) ListCollections(ctx context.Context, opts ...CallOption) (ListCollectionResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
+	return c.listCollectionsPage(ctx, 0)
+}
+
+// listCollectionsPage lists one page of collections, the first page if page
+// is zero.
+func (c *ragClient) listCollectionsPage(ctx context.Context, page int) (ListCollectionResponse, error) {
+	var params map[string]string
+	if page > 0 {
+		params = map[string]string{"page": strconv.Itoa(page)}
+	}
+
 	var response ListCollectionResponse
-	err := c.client.doRequest(ctx, http.MethodGet, "/collection/all/", nil, nil, &response)
+	err := c.client.doRequest(ctx, http.MethodGet, "/collection/all/", params, nil, &response)
 	if err != nil {
 		return ListCollectionResponse{}, err
 	}
+	if c.namespace != "" {
+		results := make([]CollectionItem, 0, len(response.Results))
+		for _, item := range response.Results {
+			// Collections of nested namespaces belong to their own views
+			if id, ok := strings.CutPrefix(item.CollectionID, c.namespace); ok && validNamespacePart(id) {
+				item.CollectionID = id
+				results = append(results, item)
+			}
+		}
+		response.Results = results
+	}
 	return response, nil
 }
 
-// QueryCollection queries a collection
-func (c *ragClient) QueryCollection(ctx context.Context, request QueryRequest) (StandardResponse, error) {
+// QueryCollection queries a collection. The collection ID may be an alias,
+// see WithAliasStore.
+func (c *ragClient) QueryCollection(ctx context.Context, request QueryRequest, opts ...CallOption) (StandardResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
 	var response StandardResponse
 
 	v:= newValidator()
@@ -64,30 +122,114 @@ func (c *ragClient) QueryCollection(ctx context.Context, request QueryRequest) (
 		return StandardResponse{}, *newValidationError("Validation Error", v.errors)
 	}
 
-	err := c.client.doRequest(ctx, http.MethodPost, "/collection/query/", nil, request, &response)
+	collectionID, err := c.qualify(request.CollectionID)
+	if err != nil {
+		return StandardResponse{}, err
+	}
+	collectionID, err = c.resolveCollection(ctx, collectionID)
+	if err != nil {
+		return StandardResponse{}, err
+	}
+	request.CollectionID = collectionID
+	if model := callOptionsFrom(ctx).model; model != "" {
+		request.Model = model
+	}
+
+	err = c.client.doRequest(ctx, http.MethodPost, "/collection/query/", nil, request, &response)
 	if err != nil {
 		return StandardResponse{}, err
 	}
 	return response, nil
 }
 
-// ChatWithCollection chats with a collection
-func (c *ragClient) ChatWithCollection(ctx context.Context, request ChatRequest) (StandardResponse, error) {
+// ChatWithCollection chats with a collection. The collection ID may be an
+// alias, see WithAliasStore.
+func (c *ragClient) ChatWithCollection(ctx context.Context, request ChatRequest, opts ...CallOption) (StandardResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
 	var response StandardResponse
 
-	err := c.client.doRequest(ctx, http.MethodPost, "/collection/chat/", nil, request, &response)
+	v := newValidator()
+
+	if !request.validate(v) {
+		return StandardResponse{}, *newValidationError("Validation Error", v.errors)
+	}
+
+	collectionID, err := c.qualify(request.CollectionID)
+	if err != nil {
+		return StandardResponse{}, err
+	}
+	collectionID, err = c.resolveCollection(ctx, collectionID)
+	if err != nil {
+		return StandardResponse{}, err
+	}
+	request.CollectionID = collectionID
+
+	err = c.client.doRequest(ctx, http.MethodPost, "/collection/chat/", nil, request, &response)
 	if err != nil {
 		return StandardResponse{}, err
 	}
 	return response, nil
 }
 
-// InsertResource inserts a resource into a collection
-func (c *ragClient) InsertResource(ctx context.Context, collectionID string, resource any, resourceType ResourceType) (ResourceInsertResponse, error) {
+// InsertResource inserts a resource into a collection. Strings of type
+// ResourceTypeFile, or detected as one with ResourceTypeAuto, are paths to
+// local files that are uploaded; URLs and strings of any other type are
+// sent as they are. The request carries an idempotency key, generated unless one is given with WithIdempotencyKey,
+// so that retries do not create duplicates. With a dedupe store, content
+// already inserted into the collection is not inserted again; the existing
+// resource is returned with Duplicate set, see WithDedupeStore.
+func (c *ragClient) InsertResource(ctx context.Context, collectionID string, resource any, resourceType ResourceType, opts ...CallOption) (ResourceInsertResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
+	collectionID, err := c.qualify(collectionID)
+	if err != nil {
+		return ResourceInsertResponse{}, err
+	}
+	if resourceType == ResourceTypeAuto {
+		if b, ok := resource.([]byte); ok {
+			resource = string(b)
+		}
+		detection, err := DetectResourceType(resource)
+		if err != nil {
+			return ResourceInsertResponse{}, err
+		}
+		resourceType = detection.Type
+	}
+
+	var hash string
+	if c.client.dedupe != nil {
+		var err error
+		hash, resource, err = contentHash(resource, resourceType)
+		if err != nil {
+			return ResourceInsertResponse{}, err
+		}
+		unlock := c.client.inserting.lock(collectionID + "\x00" + hash)
+		defer unlock()
+		resourceID, found, err := c.client.dedupe.Lookup(ctx, collectionID, hash)
+		if err != nil {
+			return ResourceInsertResponse{}, fmt.Errorf("look up content: %w", err)
+		}
+		if found {
+			return ResourceInsertResponse{ResourceID: resourceID, Success: true, Duplicate: true}, nil
+		}
+	}
+
+	// Retries of the request below reuse the key
+	if callOptionsFrom(ctx).idempotencyKey == "" {
+		key, err := GenerateID()
+		if err != nil {
+			return ResourceInsertResponse{}, err
+		}
+		ctx, _ = withCallOptions(ctx, []CallOption{WithIdempotencyKey(key)})
+	}
+
 	// Handle file upload if resource is a file path
 	var resourceURL string
 	var response ResourceInsertResponse
-	if path, ok := resource.(string); ok && !strings.HasPrefix(path, "http") {
+	if path, ok := uploadPath(resource, resourceType); ok {
 		url, err := c.client.uploadFile(ctx, collectionID, path)
 		if err != nil {
 			return ResourceInsertResponse{}, err
@@ -108,29 +250,136 @@ func (c *ragClient) InsertResource(ctx context.Context, collectionID string, res
 		Type:         resourceType,
 	}
 
-	err := c.client.doRequest(ctx, http.MethodPost, "/resource/insert/", nil, payload, &response)
+	err = c.client.doRequest(ctx, http.MethodPost, "/resource/insert/", nil, payload, &response)
 	if err != nil {
 		return ResourceInsertResponse{}, err
 	}
+
+	if hash != "" {
+		if err := c.client.dedupe.Record(ctx, collectionID, hash, response.ResourceID); err != nil {
+			return response, fmt.Errorf("resource %s not recorded for deduplication: %w", response.ResourceID, err)
+		}
+	}
 	return response, nil
 }
 
-// RemoveResource removes a resource from a collection
-func (c *ragClient) RemoveResource(ctx context.Context, request ResourceDeleteRequest) (ResourceDeleteResponse, error) {
+// uploadPath returns the local file a resource is uploaded from, if any.
+// Only ResourceTypeFile strings are paths; text that happens to name a file
+// is still text.
+func uploadPath(resource any, resourceType ResourceType) (string, bool) {
+	path, ok := resource.(string)
+	if !ok || resourceType != ResourceTypeFile || strings.HasPrefix(path, "http") {
+		return "", false
+	}
+	return path, true
+}
+
+// ListResources lists one page of the resources in a collection
+func (c *ragClient) ListResources(ctx context.Context, request ListResourcesRequest, opts ...CallOption) (ListResourcesResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
+	var response ListResourcesResponse
+
+	v := newValidator()
+
+	if !request.validate(v) {
+		return ListResourcesResponse{}, *newValidationError("Validation Error", v.errors)
+	}
+
+	collectionID, err := c.qualify(request.CollectionID)
+	if err != nil {
+		return ListResourcesResponse{}, err
+	}
+	params := map[string]string{
+		"collection_id": collectionID,
+	}
+	if request.Page > 0 {
+		params["page"] = strconv.Itoa(request.Page)
+	}
+	if request.PageSize > 0 {
+		params["page_size"] = strconv.Itoa(request.PageSize)
+	}
+
+	err = c.client.doRequest(ctx, http.MethodGet, "/resource/all/", params, nil, &response)
+	if err != nil {
+		return ListResourcesResponse{}, err
+	}
+	return response, nil
+}
+
+// GetResource retrieves a single resource from a collection
+func (c *ragClient) GetResource(ctx context.Context, collectionID, resourceID string, opts ...CallOption) (GetResourceResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
+	var response GetResourceResponse
+	collectionID, err := c.qualify(collectionID)
+	if err != nil {
+		return GetResourceResponse{}, err
+	}
+	params := map[string]string{
+		"collection_id": collectionID,
+	}
+	err = c.client.doRequest(ctx, http.MethodGet, fmt.Sprintf("/resource/get/%s/", resourceID), params, nil, &response)
+	if err != nil {
+		return GetResourceResponse{}, err
+	}
+	return response, nil
+}
+
+// RemoveResource removes a resource from a collection, and from the dedupe
+// store if any. A resource that is already gone is dropped from the store
+// too.
+func (c *ragClient) RemoveResource(ctx context.Context, request ResourceDeleteRequest, opts ...CallOption) (ResourceDeleteResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
 	var response ResourceDeleteResponse
-	err := c.client.doRequest(ctx, http.MethodDelete, "/resource/remove/", nil, request, &response)
+	collectionID, err := c.qualify(request.CollectionID)
+	if err != nil {
+		return ResourceDeleteResponse{}, err
+	}
+	request.CollectionID = collectionID
+	err = c.client.doRequest(ctx, http.MethodDelete, "/resource/remove/", nil, request, &response)
+	if err != nil && !isNotFound(err) {
+		return ResourceDeleteResponse{}, err
+	}
+
+	if c.client.dedupe != nil {
+		if forgetErr := c.client.dedupe.Forget(ctx, request.CollectionID, request.ResourceID); forgetErr != nil {
+			return response, errors.Join(err, fmt.Errorf("forget resource %s: %w", request.ResourceID, forgetErr))
+		}
+	}
 	if err != nil {
 		return ResourceDeleteResponse{}, err
 	}
 	return response, nil
 }
 
-// DeleteCollection deletes a collection
-func (c *ragClient) DeleteCollection(ctx context.Context, collectionID string) (DeleteCollectionResponse, error) {
+// DeleteCollection deletes a collection, and drops its content from the
+// dedupe store if any.
+func (c *ragClient) DeleteCollection(ctx context.Context, collectionID string, opts ...CallOption) (DeleteCollectionResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
 	var response DeleteCollectionResponse
-	err := c.client.doRequest(ctx, http.MethodDelete, "/collection/delete/", nil, map[string]any{
-		"collection_id": collectionID,
+	fullID, err := c.qualify(collectionID)
+	if err != nil {
+		return DeleteCollectionResponse{}, err
+	}
+	err = c.client.doRequest(ctx, http.MethodDelete, "/collection/delete/", nil, map[string]any{
+		"collection_id": fullID,
 	}, &response)
+	if err != nil && !isNotFound(err) {
+		return DeleteCollectionResponse{}, err
+	}
+
+	if c.client.dedupe != nil {
+		if forgetErr := c.client.dedupe.ForgetCollection(ctx, fullID); forgetErr != nil {
+			return response, errors.Join(err, fmt.Errorf("forget collection %s: %w", collectionID, forgetErr))
+		}
+	}
 	if err != nil {
 		return DeleteCollectionResponse{}, err
 	}
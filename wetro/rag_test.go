@@ -190,3 +190,97 @@ func TestRAGClient(t *testing.T) {
 		}
 	})
 }
+
+func TestRAGResources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/resource/all/":
+			if r.URL.Query().Get("collection_id") != "test-collection" {
+				http.Error(w, `{"error": "collection not found"}`, http.StatusNotFound)
+				return
+			}
+			response := ListResourcesResponse{
+				Count: 3,
+				Next:  "",
+				Results: []ResourceItem{
+					{ResourceID: "resource3", Type: ResourceTypeWeb},
+				},
+			}
+			if r.URL.Query().Get("page") != "2" {
+				response.Next = "/v1/resource/all/?page=2"
+				response.Results = []ResourceItem{
+					{ResourceID: "resource1", Type: ResourceTypeText, Size: 12},
+					{ResourceID: "resource2", Type: ResourceTypeFile, Size: 2048},
+				}
+			}
+			json.NewEncoder(w).Encode(response)
+		case "/v1/resource/get/resource1/":
+			response := GetResourceResponse{
+				Success: true,
+				Found:   true,
+				Resource: ResourceItem{
+					ResourceID: "resource1",
+					Type:       ResourceTypeText,
+					Source:     "some text",
+					Size:       9,
+					Status:     "processed",
+				},
+			}
+			json.NewEncoder(w).Encode(response)
+		default:
+			http.Error(w, "Not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+	ragClient := client.RAG
+
+	ctx := context.Background()
+
+	t.Run("ListResources", func(t *testing.T) {
+		resp, err := ragClient.ListResources(ctx, ListResourcesRequest{CollectionID: "test-collection"})
+		if err != nil {
+			t.Fatalf("ListResources failed: %v", err)
+		}
+		if resp.Count != 3 {
+			t.Errorf("Expected count 3, got %d", resp.Count)
+		}
+		if len(resp.Results) != 2 {
+			t.Errorf("Expected 2 resources, got %d", len(resp.Results))
+		}
+		if resp.Next == "" {
+			t.Error("Expected a next page")
+		}
+
+		resp, err = ragClient.ListResources(ctx, ListResourcesRequest{CollectionID: "test-collection", Page: 2})
+		if err != nil {
+			t.Fatalf("ListResources failed: %v", err)
+		}
+		if len(resp.Results) != 1 || resp.Results[0].ResourceID != "resource3" {
+			t.Errorf("Expected resource3 on page 2, got %+v", resp.Results)
+		}
+	})
+
+	t.Run("ListResourcesValidation", func(t *testing.T) {
+		_, err := ragClient.ListResources(ctx, ListResourcesRequest{})
+		if _, ok := err.(ValidationError); !ok {
+			t.Fatalf("Expected ValidationError, got %v", err)
+		}
+	})
+
+	t.Run("GetResource", func(t *testing.T) {
+		resp, err := ragClient.GetResource(ctx, "test-collection", "resource1")
+		if err != nil {
+			t.Fatalf("GetResource failed: %v", err)
+		}
+		if !resp.Found {
+			t.Error("Expected found to be true")
+		}
+		if resp.Resource.Status != "processed" {
+			t.Errorf("Expected status 'processed', got '%s'", resp.Resource.Status)
+		}
+	})
+}
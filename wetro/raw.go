@@ -0,0 +1,56 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+)
+
+// ErrNamespacedRawCall is returned by Do and DoMultipart on a client
+// returned by Namespace, which cannot tell collection IDs apart in raw
+// requests to keep them within the namespace.
+var ErrNamespacedRawCall = errors.New("raw calls are not available on a namespaced client")
+
+// Do calls an endpoint the SDK does not wrap yet, with the authentication,
+// retries and error handling of the other methods. path is relative to the
+// API version, such as "/collection/all/". query may be nil. body, if not
+// nil, is sent as JSON, and a successful response is decoded into out
+// unless it is nil. Failed responses are returned as *APIError.
+//
+// Do fails with ErrNamespacedRawCall on a client returned by Namespace;
+// raw calls go through the root client, with full collection IDs.
+func (c *Client) Do(ctx context.Context, method, path string, query map[string]string, body, out any, opts ...CallOption) error {
+	if c.RAG.namespace != "" {
+		return ErrNamespacedRawCall
+	}
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
+	// doRequest adds to the parameters, which belong to the caller
+	return c.api.doRequest(ctx, method, endpointPath(path), maps.Clone(query), body, out)
+}
+
+// DoMultipart is like Do, but sends fields as a multipart form. String
+// values are sent as they are, io.Reader values as files, named after the
+// file for an *os.File, and other values as JSON.
+func (c *Client) DoMultipart(ctx context.Context, method, path string, fields map[string]any, out any, opts ...CallOption) error {
+	if c.RAG.namespace != "" {
+		return ErrNamespacedRawCall
+	}
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
+	return c.api.doMultipartRequest(ctx, method, endpointPath(path), fields, out)
+}
+
+func endpointPath(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return "/" + path
+	}
+	return path
+}
@@ -0,0 +1,101 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token test-api-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/collection/summary/":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			json.NewEncoder(w).Encode(map[string]string{
+				"collection_id": body["collection_id"],
+				"referrer":      r.URL.Query().Get("referrer"),
+				"lang":          r.URL.Query().Get("lang"),
+			})
+		case "/v1/resource/upload/":
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				http.Error(w, `{"detail": "no file"}`, http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(file)
+			json.NewEncoder(w).Encode(map[string]string{
+				"collection_id": r.FormValue("collection_id"),
+				"filename":      header.Filename,
+				"content":       string(data),
+				"meta":          r.FormValue("meta"),
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"detail": "Not found."})
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+	ctx := context.Background()
+
+	t.Run("JSON", func(t *testing.T) {
+		query := map[string]string{"lang": "en"}
+		var out map[string]string
+		err := client.Do(ctx, http.MethodPost, "collection/summary/", query, map[string]string{"collection_id": "docs"}, &out)
+		if err != nil {
+			t.Fatalf("Do failed: %v", err)
+		}
+		if out["collection_id"] != "docs" || out["referrer"] != "GO_SDK" || out["lang"] != "en" {
+			t.Errorf("Unexpected response %v", out)
+		}
+		if len(query) != 1 {
+			t.Errorf("Expected the query to be left unchanged, got %v", query)
+		}
+	})
+
+	t.Run("Multipart", func(t *testing.T) {
+		var out map[string]string
+		err := client.DoMultipart(ctx, http.MethodPost, "/resource/upload/", map[string]any{
+			"collection_id": "docs",
+			"file":          strings.NewReader("hello"),
+			"meta":          map[string]int{"pages": 1},
+		}, &out)
+		if err != nil {
+			t.Fatalf("DoMultipart failed: %v", err)
+		}
+		if out["collection_id"] != "docs" || out["filename"] != "file" || out["content"] != "hello" || out["meta"] != `{"pages":1}` {
+			t.Errorf("Unexpected response %v", out)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		err := client.Do(ctx, http.MethodGet, "/unknown/", nil, nil, nil)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "Not found." {
+			t.Errorf("Expected a 404 APIError, got %v", err)
+		}
+	})
+
+	t.Run("Namespaced", func(t *testing.T) {
+		tenant := client.Namespace("tenant")
+		if err := tenant.Do(ctx, http.MethodGet, "/collection/all/", nil, nil, nil); !errors.Is(err, ErrNamespacedRawCall) {
+			t.Errorf("Expected ErrNamespacedRawCall from Do, got %v", err)
+		}
+		if err := tenant.DoMultipart(ctx, http.MethodPost, "/upload/", nil, nil); !errors.Is(err, ErrNamespacedRawCall) {
+			t.Errorf("Expected ErrNamespacedRawCall from DoMultipart, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+var defaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy configures how failed requests are retried. The zero value
+// does not retry.
+type RetryPolicy struct {
+
+	// Maximum number of attempts of a request, including the first. Values
+	// below 2 disable retries.
+	MaxAttempts int
+
+	// (optional) Delay before the first retry, doubled for every further
+	// retry. Defaults to 500ms.
+	InitialBackoff time.Duration
+
+	// (optional) Upper bound of the delay between two attempts, including
+	// delays asked for by a Retry-After header. Defaults to 30s.
+	MaxBackoff time.Duration
+
+	// (optional) Response status codes to retry. Defaults to 429, 500, 502,
+	// 503 and 504. Network errors are always retried.
+	RetryOn []int
+}
+
+// WithRetryPolicy sets how the client retries failed requests. By default
+// requests are not retried. See WithCallRetryPolicy to change it for a
+// single call.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *apiClient) {
+		c.retry = policy
+	}
+}
+
+// retryable reports whether the outcome of an attempt should be retried.
+func (p RetryPolicy) retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return slices.Contains(p.statuses(), resp.StatusCode)
+}
+
+// retries reports whether responses with the status code are retried.
+func (p RetryPolicy) retries(status int) bool {
+	return p.MaxAttempts >= 2 && slices.Contains(p.statuses(), status)
+}
+
+func (p RetryPolicy) statuses() []int {
+	if len(p.RetryOn) == 0 {
+		return defaultRetryStatuses
+	}
+	return p.RetryOn
+}
+
+// retryPolicy returns the policy of a call: the one it was given with
+// WithCallRetryPolicy, or the client's.
+func (c *apiClient) retryPolicy(o callOptions) RetryPolicy {
+	if o.retry != nil {
+		return *o.retry
+	}
+	return c.retry
+}
+
+// backoff returns the delay before the retry following attempt, honoring a
+// Retry-After header given in seconds.
+func (p RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, maxBackoff)
+		}
+	}
+
+	delay := p.InitialBackoff
+	if delay <= 0 {
+		delay = defaultInitialBackoff
+	}
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
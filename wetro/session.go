@@ -0,0 +1,286 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ChatSessionOptions controls how much of the history a ChatSession sends
+// with every message. The full history is always kept in the session.
+type ChatSessionOptions struct {
+
+	// (optional) Only send the most recent turns, a turn being a user message
+	// and the assistant reply to it. Zero sends every turn.
+	MaxTurns int `json:"max_turns,omitempty"`
+
+	// (optional) Only send as many of the most recent messages as fit in this
+	// estimated number of tokens. Zero means no limit.
+	MaxTokens int `json:"max_tokens,omitempty"`
+
+	// (optional) Where to persist the history. Every turn is appended to the
+	// store under SessionID, which is then required.
+	Store ChatStore `json:"-"`
+
+	// The ID the history is stored under.
+	SessionID string `json:"session_id,omitempty"`
+
+	// (optional) Keep at most this many of the most recent messages, both in
+	// the session and in the store. Zero keeps every message.
+	MaxStoredMessages int `json:"max_stored_messages,omitempty"`
+
+	// (optional) Compacts the history before every message. The compacted
+	// history replaces the history of the session; a store keeps the full
+	// transcript.
+	Compactor HistoryCompactor `json:"-"`
+}
+
+// ChatSession is a conversation with a collection. It keeps the chat
+// history and sends it along with every message, so callers do not have to
+// maintain ChatRequest.ChatHistory themselves. A ChatSession is safe for
+// concurrent use; messages sent concurrently are processed one at a time.
+type ChatSession struct {
+	rag          *ragClient
+	collectionID string
+	opts         ChatSessionOptions
+
+	// sendMu serializes turns, mu guards the history.
+	sendMu  sync.Mutex
+	mu      sync.Mutex
+	history []Message
+}
+
+// NewChatSession starts a new chat session with a collection.
+func (c *ragClient) NewChatSession(collectionID string, opts ChatSessionOptions) *ChatSession {
+	return &ChatSession{rag: c, collectionID: collectionID, opts: opts}
+}
+
+// ResumeChatSession continues a chat session whose history was persisted in
+// opts.Store under opts.SessionID. It returns ErrSessionNotFound if the
+// store has no such session.
+func (c *ragClient) ResumeChatSession(ctx context.Context, collectionID string, opts ChatSessionOptions) (*ChatSession, error) {
+	if opts.Store == nil || opts.SessionID == "" {
+		return nil, errors.New("resuming a chat session requires a store and a session ID")
+	}
+	history, err := opts.Store.Load(ctx, opts.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	session := c.NewChatSession(collectionID, opts)
+	session.history = history
+	return session, nil
+}
+
+// CollectionID returns the collection the session chats with.
+func (s *ChatSession) CollectionID() string {
+	return s.collectionID
+}
+
+// Send sends a message to the collection along with the windowed history,
+// and records the message and the assistant reply once the call succeeds.
+// If the turn cannot be persisted, the response is returned together with
+// the store error.
+func (s *ChatSession) Send(ctx context.Context, message string) (StandardResponse, error) {
+	if message == "" {
+		return StandardResponse{}, errors.New("message should not be empty")
+	}
+	if s.opts.Store != nil && s.opts.SessionID == "" {
+		return StandardResponse{}, errors.New("a chat session with a store requires a session ID")
+	}
+
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	if err := s.compact(ctx); err != nil {
+		return StandardResponse{}, err
+	}
+
+	s.mu.Lock()
+	history := windowHistory(s.history, s.opts)
+	s.mu.Unlock()
+
+	response, err := s.rag.ChatWithCollection(ctx, ChatRequest{
+		CollectionID: s.collectionID,
+		Message:      message,
+		ChatHistory:  history,
+	})
+	if err != nil {
+		return StandardResponse{}, err
+	}
+
+	turn := []Message{
+		NewMessage(RoleUser, message),
+		NewMessage(RoleAssistant, responseText(response.Response)),
+	}
+
+	s.mu.Lock()
+	s.history = append(s.history, turn...)
+	if max := s.opts.MaxStoredMessages; max > 0 && len(s.history) > max {
+		s.history = s.history[len(s.history)-max:]
+	}
+	s.mu.Unlock()
+
+	if err := s.persist(ctx, turn); err != nil {
+		return response, fmt.Errorf("chat history not saved: %w", err)
+	}
+	return response, nil
+}
+
+// compact runs the compactor, if any, over the session history. It must be
+// called with sendMu held, so the history cannot grow in the meantime.
+func (s *ChatSession) compact(ctx context.Context) error {
+	if s.opts.Compactor == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	history := copyHistory(s.history)
+	s.mu.Unlock()
+
+	compacted, err := s.opts.Compactor.Compact(ctx, history)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.history = compacted
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *ChatSession) persist(ctx context.Context, turn []Message) error {
+	if s.opts.Store == nil {
+		return nil
+	}
+	if err := s.opts.Store.Append(ctx, s.opts.SessionID, turn...); err != nil {
+		return err
+	}
+	if s.opts.MaxStoredMessages > 0 {
+		return s.opts.Store.Trim(ctx, s.opts.SessionID, s.opts.MaxStoredMessages)
+	}
+	return nil
+}
+
+// History returns a copy of the full chat history.
+func (s *ChatSession) History() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyHistory(s.history)
+}
+
+// Reset clears the chat history, deleting it from the store if there is one.
+func (s *ChatSession) Reset(ctx context.Context) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	s.mu.Lock()
+	s.history = nil
+	s.mu.Unlock()
+
+	if s.opts.Store != nil {
+		return s.opts.Store.Delete(ctx, s.opts.SessionID)
+	}
+	return nil
+}
+
+// Fork returns a new session with a copy of the current history. The two
+// sessions evolve independently from then on. The fork is not bound to the
+// store of the original session.
+func (s *ChatSession) Fork() *ChatSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	opts := s.opts
+	opts.Store, opts.SessionID = nil, ""
+	return &ChatSession{
+		rag:          s.rag,
+		collectionID: s.collectionID,
+		opts:         opts,
+		history:      copyHistory(s.history),
+	}
+}
+
+type chatSessionState struct {
+	CollectionID string             `json:"collection_id"`
+	Options      ChatSessionOptions `json:"options"`
+	History      []Message          `json:"history"`
+}
+
+// MarshalJSON serializes the collection, options and history of the session.
+func (s *ChatSession) MarshalJSON() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return json.Marshal(chatSessionState{
+		CollectionID: s.collectionID,
+		Options:      s.opts,
+		History:      s.history,
+	})
+}
+
+// RestoreChatSession recreates a session serialized with json.Marshal.
+// The store is not serialized; set it again on a restored session with
+// ResumeChatSession instead when the history is persisted.
+func (c *ragClient) RestoreChatSession(data []byte) (*ChatSession, error) {
+	var state chatSessionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid chat session: %w", err)
+	}
+	if state.CollectionID == "" {
+		return nil, errors.New("invalid chat session: missing collection_id")
+	}
+	session := c.NewChatSession(state.CollectionID, state.Options)
+	session.history = state.History
+	return session, nil
+}
+
+// windowHistory returns the most recent messages allowed by opts.
+func windowHistory(history []Message, opts ChatSessionOptions) []Message {
+	start := 0
+	if opts.MaxTurns > 0 && len(history) > 2*opts.MaxTurns {
+		start = len(history) - 2*opts.MaxTurns
+	}
+	if opts.MaxTokens > 0 {
+		// The chat endpoint does not take a model, so the default
+		// estimator is used.
+		return TrimHistoryToTokens("", history[start:], opts.MaxTokens)
+	}
+	return copyHistory(history[start:])
+}
+
+func copyHistory(history []Message) []Message {
+	out := make([]Message, len(history))
+	for i, m := range history {
+		out[i] = make(Message, len(m))
+		for k, v := range m {
+			out[i][k] = v
+		}
+	}
+	return out
+}
+
+// responseText extracts the text of an API response, which is either a
+// plain string or an object holding the text under a well known key.
+func responseText(response any) string {
+	switch r := response.(type) {
+	case nil:
+		return ""
+	case string:
+		return r
+	case map[string]any:
+		for _, key := range []string{"response", "content", "text", "answer"} {
+			if text, ok := r[key].(string); ok {
+				return text
+			}
+		}
+	}
+	b, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Sprintf("%v", response)
+	}
+	return string(b)
+}
@@ -0,0 +1,149 @@
+package wetro
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newChatServer returns a client whose chat endpoint answers every message
+// with "re: <message>" and passes the received requests to onChat.
+func newChatServer(t *testing.T, onChat func(ChatRequest)) *Client {
+	t.Helper()
+
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/collection/chat/" {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		var req ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if onChat != nil {
+			mu.Lock()
+			onChat(req)
+			mu.Unlock()
+		}
+		json.NewEncoder(w).Encode(StandardResponse{
+			Success:  true,
+			Tokens:   5,
+			Response: map[string]string{"response": "re: " + req.Message},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return NewClient("test-api-key", func(c *apiClient) {
+		c.baseURL = server.URL + "/"
+	})
+}
+
+func TestChatSession(t *testing.T) {
+	var last ChatRequest
+	client := newChatServer(t, func(req ChatRequest) { last = req })
+	ctx := context.Background()
+
+	t.Run("History", func(t *testing.T) {
+		session := client.RAG.NewChatSession("docs", ChatSessionOptions{})
+		for _, msg := range []string{"one", "two", "three"} {
+			if _, err := session.Send(ctx, msg); err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+		}
+		history := session.History()
+		if len(history) != 6 {
+			t.Fatalf("Expected 6 messages, got %d", len(history))
+		}
+		if history[4]["role"] != "user" || history[5]["content"] != "re: three" {
+			t.Errorf("Unexpected last turn %v", history[4:])
+		}
+		if len(last.ChatHistory) != 4 || last.Message != "three" {
+			t.Errorf("Expected the previous 2 turns to be sent, got %v", last.ChatHistory)
+		}
+	})
+
+	t.Run("MaxTurns", func(t *testing.T) {
+		session := client.RAG.NewChatSession("docs", ChatSessionOptions{MaxTurns: 1})
+		for _, msg := range []string{"one", "two", "three"} {
+			session.Send(ctx, msg)
+		}
+		if len(last.ChatHistory) != 2 || last.ChatHistory[0]["content"] != "two" {
+			t.Errorf("Expected only the last turn to be sent, got %v", last.ChatHistory)
+		}
+		if len(session.History()) != 6 {
+			t.Error("Expected the full history to be kept")
+		}
+	})
+
+	t.Run("MaxTokens", func(t *testing.T) {
+		session := client.RAG.NewChatSession("docs", ChatSessionOptions{MaxTokens: 30})
+		session.Send(ctx, strings.Repeat("long ", 40))
+		session.Send(ctx, "short")
+		session.Send(ctx, "again")
+		for _, m := range last.ChatHistory {
+			if strings.Contains(m["content"], "long") {
+				t.Errorf("Expected the oversized turn to be left out, got %v", last.ChatHistory)
+			}
+		}
+		if len(last.ChatHistory) != 2 {
+			t.Errorf("Expected the short turn to be sent, got %v", last.ChatHistory)
+		}
+	})
+
+	t.Run("ForkResetRestore", func(t *testing.T) {
+		session := client.RAG.NewChatSession("docs", ChatSessionOptions{MaxTurns: 3})
+		session.Send(ctx, "one")
+
+		fork := session.Fork()
+		fork.Send(ctx, "two")
+		if len(session.History()) != 2 || len(fork.History()) != 4 {
+			t.Errorf("Expected fork to be independent, got %d and %d messages", len(session.History()), len(fork.History()))
+		}
+
+		data, err := json.Marshal(fork)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		restored, err := client.RAG.RestoreChatSession(data)
+		if err != nil {
+			t.Fatalf("RestoreChatSession failed: %v", err)
+		}
+		if restored.CollectionID() != "docs" || len(restored.History()) != 4 || restored.opts.MaxTurns != 3 {
+			t.Errorf("Unexpected restored session %s", data)
+		}
+
+		if err := session.Reset(ctx); err != nil {
+			t.Fatalf("Reset failed: %v", err)
+		}
+		if len(session.History()) != 0 {
+			t.Error("Expected Reset to clear the history")
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		session := client.RAG.NewChatSession("docs", ChatSessionOptions{})
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				session.Send(ctx, fmt.Sprintf("message %d", i))
+			}(i)
+		}
+		wg.Wait()
+
+		history := session.History()
+		if len(history) != 20 {
+			t.Fatalf("Expected 20 messages, got %d", len(history))
+		}
+		for i := 0; i < len(history); i += 2 {
+			if history[i+1]["content"] != "re: "+history[i]["content"] {
+				t.Errorf("Expected turns to stay paired, got %v and %v", history[i], history[i+1])
+			}
+		}
+	})
+}
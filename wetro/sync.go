@@ -0,0 +1,229 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"time"
+)
+
+// SyncAction describes what SyncDirectory does with a file.
+type SyncAction string
+
+const (
+	SyncAdd    SyncAction = "add"
+	SyncUpdate SyncAction = "update"
+	SyncDelete SyncAction = "delete"
+)
+
+// SyncItem is a single step of a sync plan.
+type SyncItem struct {
+	Action SyncAction
+
+	// The slash-separated path relative to the synced directory
+	Path string
+
+	// The resource currently holding the file, empty for additions
+	ResourceID string
+
+	// The content hash of the local file, empty for deletions
+	Hash string
+
+	Size int64
+}
+
+// SyncPlan lists the changes needed to bring a collection in line with a
+// local directory.
+type SyncPlan struct {
+	Add    []SyncItem
+	Update []SyncItem
+	// Deleted files, and resources of changed files left over by an earlier
+	// sync
+	Delete []SyncItem
+
+	// Number of files whose content has not changed since the last sync
+	Unchanged int
+}
+
+// Empty reports whether the plan has nothing to do.
+func (p SyncPlan) Empty() bool {
+	return len(p.Add) == 0 && len(p.Update) == 0 && len(p.Delete) == 0
+}
+
+// SyncOptions controls SyncDirectory. IngestOptions.ManifestPath is required:
+// the manifest it points to holds the content hashes of the previous sync.
+type SyncOptions struct {
+	IngestOptions
+
+	// Compute the plan without inserting, removing or saving anything.
+	DryRun bool
+}
+
+// SyncResponse contains the plan of a sync and, unless it was a dry run,
+// the outcome of applying it.
+type SyncResponse struct {
+	Plan     SyncPlan
+	Manifest IngestManifest
+	Skipped  []SkippedFile
+	Failed   []IngestFailure
+
+	// Summary of the inserts made for added and updated files
+	Summary BulkInsertSummary
+
+	// Number of resources removed for updated and deleted files
+	Removed int
+}
+
+// SyncDirectory brings a collection in line with the files under root. Only
+// new and changed files are inserted; the resources of changed and deleted
+// files are removed. Files are compared through the content hashes recorded
+// in the manifest at opts.ManifestPath, which is created on the first sync
+// and rewritten after every sync that is not a dry run. Files that no longer
+// match the include and exclude patterns are treated as deleted. Resources
+// that could not be removed stay in the manifest and are removed on the
+// next sync.
+func (c *ragClient) SyncDirectory(ctx context.Context, collectionID, root string, opts SyncOptions, callOpts ...CallOption) (SyncResponse, error) {
+	ctx, cancel := withCallOptions(ctx, callOpts)
+	defer cancel()
+
+	v := newValidator()
+	v.check(collectionID != "", "collection_id", "collection_id should not be empty")
+	v.check(opts.ManifestPath != "", "manifest_path", "manifest_path should not be empty")
+	if !v.valid() {
+		return SyncResponse{}, *newValidationError("Validation Error", v.errors)
+	}
+
+	manifest, err := LoadManifest(opts.ManifestPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		manifest = IngestManifest{CollectionID: collectionID, Files: make(map[string]ManifestEntry)}
+	} else if err != nil {
+		return SyncResponse{}, err
+	}
+	if manifest.CollectionID != collectionID {
+		return SyncResponse{}, fmt.Errorf("manifest %s belongs to collection %s, not %s", opts.ManifestPath, manifest.CollectionID, collectionID)
+	}
+
+	files, skipped, err := collectFiles(root, opts.IngestOptions)
+	if err != nil {
+		return SyncResponse{}, err
+	}
+
+	plan, byPath, err := planSync(manifest, files)
+	if err != nil {
+		return SyncResponse{}, err
+	}
+
+	response := SyncResponse{Plan: plan, Manifest: manifest, Skipped: skipped}
+	if opts.DryRun || plan.Empty() {
+		return response, nil
+	}
+
+	// Insert new and changed files first, so a changed file keeps its old
+	// resource until the new one is in place.
+	pending := append(append([]SyncItem{}, plan.Add...), plan.Update...)
+	resources := make([]Resource, len(pending))
+	for i, item := range pending {
+		resources[i] = Resource{Source: byPath[item.Path].path, Type: ResourceTypeFile}
+	}
+	bulk, err := c.InsertResources(ctx, collectionID, resources, opts.Bulk, idempotencyOptions(ctx, "")...)
+	if err != nil {
+		return response, err
+	}
+	response.Summary = bulk.Summary
+
+	var stale []SyncItem
+	for i, result := range bulk.Results {
+		item := pending[i]
+		if result.Err != nil {
+			response.Failed = append(response.Failed, IngestFailure{Path: item.Path, Err: result.Err})
+			continue
+		}
+		manifest.Files[item.Path] = ManifestEntry{
+			ResourceID: result.ResourceID,
+			Size:       item.Size,
+			Hash:       item.Hash,
+			Tokens:     result.Tokens,
+		}
+		if item.Action == SyncUpdate {
+			stale = append(stale, item)
+		}
+	}
+
+	// Deleted files are dropped from the manifest once their resource is
+	// gone. Resources no file refers to any more are kept in the stale list
+	// until they are.
+	var leftover []StaleResource
+	for _, item := range append(stale, plan.Delete...) {
+		current, tracked := manifest.Files[item.Path]
+		tracked = tracked && current.ResourceID == item.ResourceID
+
+		_, err := c.RemoveResource(ctx, ResourceDeleteRequest{CollectionID: collectionID, ResourceID: item.ResourceID})
+		if err != nil && !isNotFound(err) {
+			response.Failed = append(response.Failed, IngestFailure{Path: item.Path, Err: fmt.Errorf("remove resource %s: %w", item.ResourceID, err)})
+			if !tracked {
+				leftover = append(leftover, StaleResource{Path: item.Path, ResourceID: item.ResourceID})
+			}
+			continue
+		}
+		response.Removed++
+		if tracked {
+			delete(manifest.Files, item.Path)
+		}
+	}
+	manifest.Stale = leftover
+
+	manifest.Root = root
+	manifest.UpdatedAt = time.Now().UTC()
+	response.Manifest = manifest
+	if err := manifest.Save(opts.ManifestPath); err != nil {
+		return response, err
+	}
+	return response, nil
+}
+
+// planSync compares the files on disk against the manifest.
+func planSync(manifest IngestManifest, files []localFile) (SyncPlan, map[string]localFile, error) {
+	var plan SyncPlan
+	byPath := make(map[string]localFile, len(files))
+
+	for _, file := range files {
+		byPath[file.relPath] = file
+
+		hash, err := hashFile(file.path)
+		if err != nil {
+			return SyncPlan{}, nil, err
+		}
+		item := SyncItem{Path: file.relPath, Hash: hash, Size: file.size}
+
+		entry, ok := manifest.Files[file.relPath]
+		switch {
+		case !ok:
+			item.Action = SyncAdd
+			plan.Add = append(plan.Add, item)
+		case entry.Hash != hash:
+			item.Action = SyncUpdate
+			item.ResourceID = entry.ResourceID
+			plan.Update = append(plan.Update, item)
+		default:
+			plan.Unchanged++
+		}
+	}
+
+	for rel, entry := range manifest.Files {
+		if _, ok := byPath[rel]; !ok {
+			plan.Delete = append(plan.Delete, SyncItem{Action: SyncDelete, Path: rel, ResourceID: entry.ResourceID, Size: entry.Size})
+		}
+	}
+	for _, stale := range manifest.Stale {
+		plan.Delete = append(plan.Delete, SyncItem{Action: SyncDelete, Path: stale.Path, ResourceID: stale.ResourceID})
+	}
+	sort.Slice(plan.Delete, func(i, j int) bool { return plan.Delete[i].Path < plan.Delete[j].Path })
+
+	return plan, byPath, nil
+}
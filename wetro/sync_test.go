@@ -0,0 +1,194 @@
+package wetro
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSyncDirectory(t *testing.T) {
+	var removed []string
+	_, client := newUploadServer(t, func(req ResourceDeleteRequest) {
+		removed = append(removed, req.ResourceID)
+	})
+
+	ctx := context.Background()
+	root := t.TempDir()
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+	opts := SyncOptions{IngestOptions: IngestOptions{ManifestPath: manifestPath}}
+
+	writeTestFiles(t, root, map[string]string{
+		"a.md":     "first",
+		"b.md":     "second",
+		"sub/c.md": "third",
+	})
+
+	resp, err := client.RAG.SyncDirectory(ctx, "docs", root, opts)
+	if err != nil {
+		t.Fatalf("initial SyncDirectory failed: %v", err)
+	}
+	if len(resp.Plan.Add) != 3 || len(resp.Manifest.Files) != 3 {
+		t.Fatalf("Expected 3 files added, got plan %+v", resp.Plan)
+	}
+
+	// Change a.md, delete b.md and add d.md.
+	writeTestFiles(t, root, map[string]string{
+		"a.md": "first, edited",
+		"d.md": "fourth",
+	})
+	if err := os.Remove(filepath.Join(root, "b.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("DryRun", func(t *testing.T) {
+		dry := opts
+		dry.DryRun = true
+		resp, err := client.RAG.SyncDirectory(ctx, "docs", root, dry)
+		if err != nil {
+			t.Fatalf("SyncDirectory failed: %v", err)
+		}
+		plan := resp.Plan
+		if len(plan.Add) != 1 || plan.Add[0].Path != "d.md" {
+			t.Errorf("Expected d.md to be added, got %+v", plan.Add)
+		}
+		if len(plan.Update) != 1 || plan.Update[0].Path != "a.md" || plan.Update[0].ResourceID != "res-a.md-1" {
+			t.Errorf("Expected a.md to be updated, got %+v", plan.Update)
+		}
+		if len(plan.Delete) != 1 || plan.Delete[0].Path != "b.md" {
+			t.Errorf("Expected b.md to be deleted, got %+v", plan.Delete)
+		}
+		if plan.Unchanged != 1 {
+			t.Errorf("Expected 1 unchanged file, got %d", plan.Unchanged)
+		}
+
+		manifest, err := LoadManifest(manifestPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := manifest.Files["d.md"]; ok || len(removed) != 0 {
+			t.Error("Expected dry run to leave the manifest and collection untouched")
+		}
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		resp, err := client.RAG.SyncDirectory(ctx, "docs", root, opts)
+		if err != nil {
+			t.Fatalf("SyncDirectory failed: %v", err)
+		}
+		if len(resp.Failed) != 0 {
+			t.Fatalf("Unexpected failures %+v", resp.Failed)
+		}
+		if resp.Removed != 2 || len(removed) != 2 {
+			t.Errorf("Expected 2 removals, got %v", removed)
+		}
+
+		manifest, err := LoadManifest(manifestPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if manifest.Files["a.md"].ResourceID != "res-a.md-2" {
+			t.Errorf("Expected a.md to point to its new resource, got %+v", manifest.Files["a.md"])
+		}
+		if _, ok := manifest.Files["b.md"]; ok {
+			t.Error("Expected b.md to be removed from the manifest")
+		}
+		if _, ok := manifest.Files["d.md"]; !ok {
+			t.Error("Expected d.md to be added to the manifest")
+		}
+
+		resp, err = client.RAG.SyncDirectory(ctx, "docs", root, opts)
+		if err != nil {
+			t.Fatalf("SyncDirectory failed: %v", err)
+		}
+		if !resp.Plan.Empty() || resp.Plan.Unchanged != 3 {
+			t.Errorf("Expected nothing left to sync, got %+v", resp.Plan)
+		}
+	})
+
+	t.Run("ManifestCollectionMismatch", func(t *testing.T) {
+		if _, err := client.RAG.SyncDirectory(ctx, "other", root, opts); err == nil {
+			t.Error("Expected an error when syncing against another collection's manifest")
+		}
+	})
+}
+
+// failRemovals fails resource removals while it is set.
+type failRemovals struct {
+	fail *bool
+}
+
+func (f failRemovals) RoundTrip(r *http.Request) (*http.Response, error) {
+	if *f.fail && r.URL.Path == "/v1/resource/remove/" {
+		return &http.Response{
+			StatusCode: http.StatusInternalServerError,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"error": "boom"}`)),
+			Request:    r,
+		}, nil
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestSyncDirectoryStaleResources(t *testing.T) {
+	var removed []string
+	_, client := newUploadServer(t, func(req ResourceDeleteRequest) {
+		removed = append(removed, req.ResourceID)
+	})
+	failing := false
+	client.RAG.client.httpClient = &http.Client{Transport: failRemovals{&failing}}
+
+	ctx := context.Background()
+	root := t.TempDir()
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+	opts := SyncOptions{IngestOptions: IngestOptions{ManifestPath: manifestPath}}
+
+	writeTestFiles(t, root, map[string]string{"a.md": "first", "b.md": "second"})
+	if _, err := client.RAG.SyncDirectory(ctx, "docs", root, opts); err != nil {
+		t.Fatalf("initial SyncDirectory failed: %v", err)
+	}
+
+	// The old resource of a.md and the one of b.md cannot be removed
+	writeTestFiles(t, root, map[string]string{"a.md": "first, edited"})
+	os.Remove(filepath.Join(root, "b.md"))
+	failing = true
+	resp, err := client.RAG.SyncDirectory(ctx, "docs", root, opts)
+	if err != nil {
+		t.Fatalf("SyncDirectory failed: %v", err)
+	}
+	if len(resp.Failed) != 2 || resp.Removed != 0 {
+		t.Errorf("Expected 2 failed removals, got %+v", resp.Failed)
+	}
+
+	manifest, _ := LoadManifest(manifestPath)
+	if manifest.Files["a.md"].ResourceID != "res-a.md-2" {
+		t.Errorf("Expected a.md to point to its new resource, got %+v", manifest.Files["a.md"])
+	}
+	if len(manifest.Stale) != 1 || manifest.Stale[0].ResourceID != "res-a.md-1" {
+		t.Errorf("Expected the old resource of a.md to be kept as stale, got %+v", manifest.Stale)
+	}
+	if _, ok := manifest.Files["b.md"]; !ok {
+		t.Error("Expected b.md to stay in the manifest until its resource is removed")
+	}
+
+	// The next sync removes both
+	failing = false
+	resp, err = client.RAG.SyncDirectory(ctx, "docs", root, opts)
+	if err != nil {
+		t.Fatalf("SyncDirectory failed: %v", err)
+	}
+	if len(resp.Plan.Delete) != 2 || resp.Removed != 2 || len(resp.Plan.Add)+len(resp.Plan.Update) != 0 {
+		t.Errorf("Expected the 2 leftover resources to be removed, got %+v", resp.Plan)
+	}
+	if len(removed) != 2 || removed[0] != "res-a.md-1" || removed[1] != "res-b.md-1" {
+		t.Errorf("Unexpected removals %v", removed)
+	}
+
+	manifest, _ = LoadManifest(manifestPath)
+	if _, ok := manifest.Files["b.md"]; ok || len(manifest.Stale) != 0 || len(manifest.Files) != 1 {
+		t.Errorf("Expected only a.md to be left, got %+v", manifest)
+	}
+}
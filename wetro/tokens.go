@@ -0,0 +1,299 @@
+// Copyright 2025 Richd0tcom. All rights reserved.
+// Use of this source code is governed by an MIT style
+// license that can be found in the LICENSE file.
+
+package wetro
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ModelFamily groups chat models that share a tokenizer closely enough to be
+// estimated the same way.
+type ModelFamily string
+
+const (
+	ModelFamilyOpenAI ModelFamily = "openai"
+	ModelFamilyClaude ModelFamily = "claude"
+	ModelFamilyLlama  ModelFamily = "llama"
+	ModelFamilyOther  ModelFamily = "other"
+)
+
+// Family returns the tokenizer family of the model. Unknown models belong
+// to ModelFamilyOther.
+func (m ChatModel) Family() ModelFamily {
+	name := strings.ToLower(string(m))
+	switch {
+	case strings.HasPrefix(name, "gpt-"), strings.HasPrefix(name, "chatgpt-"),
+		strings.HasPrefix(name, "o1"), strings.HasPrefix(name, "o3"):
+		return ModelFamilyOpenAI
+	case strings.HasPrefix(name, "claude-"):
+		return ModelFamilyClaude
+	case strings.Contains(name, "llama"):
+		return ModelFamilyLlama
+	}
+	return ModelFamilyOther
+}
+
+// ContextWindow returns the approximate number of tokens the model accepts
+// in a single request, or 0 for unknown models.
+func (m ChatModel) ContextWindow() int {
+	switch m {
+	case GPT4:
+		return 8192
+	case GPT35Turbo:
+		return 16385
+	case O1, O3Mini:
+		return 200000
+	case Llama370B8192, Llama38B8192, LlamaGuard38B:
+		return 8192
+	case Mixtral8x7B32768:
+		return 32768
+	}
+	switch m.Family() {
+	case ModelFamilyClaude:
+		return 200000
+	case ModelFamilyOpenAI, ModelFamilyLlama:
+		return 128000
+	}
+	if strings.HasPrefix(string(m), "qwen-") {
+		return 128000
+	}
+	return 0
+}
+
+// TokenEstimator estimates how many tokens a text takes up, without calling
+// the API.
+type TokenEstimator interface {
+	EstimateTokens(text string) int
+}
+
+// bpeEstimator approximates a byte pair encoding tokenizer. Text is split
+// into words, numbers, punctuation and whitespace the way BPE tokenizers
+// pre-tokenize it, and every piece is priced by its length.
+type bpeEstimator struct {
+
+	// Letter runs up to this length are a single token
+	wholeWord int
+
+	// Average characters per token for the remainder of longer words
+	charsPerToken float64
+
+	// Digits grouped into a single token (1 splits every digit)
+	digitsPerToken int
+
+	// Whether a space before a number is a token of its own
+	spaceBeforeNumber bool
+
+	// Tokens added for every chat message (role and separators)
+	messageOverhead int
+}
+
+var estimators = map[ModelFamily]*bpeEstimator{
+	// cl100k_base / o200k_base
+	ModelFamilyOpenAI: {wholeWord: 7, charsPerToken: 4.0, digitsPerToken: 3, spaceBeforeNumber: true, messageOverhead: 4},
+	// Claude tokenizes slightly more finely than cl100k
+	ModelFamilyClaude: {wholeWord: 6, charsPerToken: 3.5, digitsPerToken: 3, spaceBeforeNumber: true, messageOverhead: 5},
+	// Llama 3 uses a 128k tiktoken vocabulary close to cl100k
+	ModelFamilyLlama: {wholeWord: 7, charsPerToken: 4.0, digitsPerToken: 3, spaceBeforeNumber: true, messageOverhead: 5},
+	// SentencePiece vocabularies such as Mixtral split every digit
+	ModelFamilyOther: {wholeWord: 5, charsPerToken: 3.0, digitsPerToken: 1, messageOverhead: 5},
+}
+
+// EstimatorFor returns the token estimator of the model's family.
+// An empty model uses the OpenAI estimator.
+func EstimatorFor(model ChatModel) TokenEstimator {
+	return estimatorFor(model)
+}
+
+// EstimateTokens estimates the number of tokens text takes up for model.
+func EstimateTokens(model ChatModel, text string) int {
+	return estimatorFor(model).EstimateTokens(text)
+}
+
+// EstimateHistoryTokens estimates the tokens taken up by a chat history,
+// including the per-message overhead.
+func EstimateHistoryTokens(model ChatModel, history []Message) int {
+	e := estimatorFor(model)
+	tokens := 0
+	for _, m := range history {
+		tokens += e.EstimateTokens(m.Content()) + e.messageOverhead
+	}
+	return tokens
+}
+
+// EstimatedTokens estimates the prompt tokens of a text generation request.
+func (r TextGenerationRequest) EstimatedTokens() int {
+	e := estimatorFor(r.Model)
+	tokens := 0
+	for _, m := range r.Messages {
+		tokens += e.EstimateTokens(m.Content) + e.messageOverhead
+	}
+	return tokens
+}
+
+// EstimatedTokens estimates the prompt tokens of the query itself, not
+// counting the context the API retrieves from the collection.
+func (r QueryRequest) EstimatedTokens() int {
+	e := estimatorFor(r.Model)
+	return e.EstimateTokens(r.Query) + e.messageOverhead
+}
+
+// TrimHistoryToTokens returns the most recent messages of history whose
+// estimated tokens fit in maxTokens.
+func TrimHistoryToTokens(model ChatModel, history []Message, maxTokens int) []Message {
+	e := estimatorFor(model)
+	tokens := 0
+	start := len(history)
+	for start > 0 {
+		tokens += e.EstimateTokens(history[start-1].Content()) + e.messageOverhead
+		if tokens > maxTokens {
+			break
+		}
+		start--
+	}
+	return copyHistory(history[start:])
+}
+
+func estimatorFor(model ChatModel) *bpeEstimator {
+	if model == "" {
+		return estimators[ModelFamilyOpenAI]
+	}
+	return estimators[model.Family()]
+}
+
+// EstimateTokens implements TokenEstimator.
+func (e *bpeEstimator) EstimateTokens(text string) int {
+	runes := []rune(text)
+	tokens := 0
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isCJK(r):
+			tokens++
+			i++
+
+		case unicode.IsLetter(r) || unicode.IsMark(r) || r == '\'':
+			j := i
+			nonASCII := 0
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsMark(runes[j]) || (runes[j] == '\'' && j > i)) && !isCJK(runes[j]) {
+				if runes[j] > unicode.MaxASCII {
+					nonASCII++
+				}
+				j++
+			}
+			tokens += e.wordTokens(j-i, nonASCII)
+			i = j
+
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			tokens += (j - i + e.digitsPerToken - 1) / e.digitsPerToken
+			if e.spaceBeforeNumber && i > 0 && runes[i-1] == ' ' {
+				tokens++
+			}
+			i = j
+
+		case unicode.IsSpace(r):
+			j := i
+			newlines := 0
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				if runes[j] == '\n' {
+					newlines++
+				}
+				j++
+			}
+			// A single space is merged into the next word; longer runs
+			// and line breaks are tokens of their own.
+			if newlines > 0 || j-i > 1 {
+				tokens++
+			}
+			i = j
+
+		default:
+			j := i
+			for j < len(runes) && runes[j] == r {
+				j++
+			}
+			tokens += (j - i + 3) / 4
+			i = j
+		}
+	}
+	return tokens
+}
+
+// wordTokens prices a run of n letters, nonASCII of which are outside ASCII
+// and take up more than one byte in the vocabulary.
+func (e *bpeEstimator) wordTokens(n, nonASCII int) int {
+	if nonASCII*2 > n {
+		return (n + 1) / 2
+	}
+	if n <= e.wholeWord {
+		return 1
+	}
+	rest := float64(n - e.wholeWord)
+	return 1 + int(rest/e.charsPerToken+0.999)
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+// ErrTokenBudgetExceeded is returned by TokenBudget.Reserve when a call
+// would use more tokens than are left.
+var ErrTokenBudgetExceeded = errors.New("token budget exceeded")
+
+// TokenBudget enforces a limit on the tokens spent across many calls. Reserve
+// the estimated tokens before a call and Commit the tokens the API reports
+// afterwards. A TokenBudget is safe for concurrent use.
+type TokenBudget struct {
+	mu       sync.Mutex
+	limit    int
+	used     int
+	reserved int
+}
+
+// NewTokenBudget returns a budget of limit tokens.
+func NewTokenBudget(limit int) *TokenBudget {
+	return &TokenBudget{limit: limit}
+}
+
+// Reserve sets aside estimated tokens for a call, or returns
+// ErrTokenBudgetExceeded if they are not available.
+func (b *TokenBudget) Reserve(estimated int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.used+b.reserved+estimated > b.limit {
+		return fmt.Errorf("%w: %d tokens needed, %d left", ErrTokenBudgetExceeded, estimated, b.limit-b.used-b.reserved)
+	}
+	b.reserved += estimated
+	return nil
+}
+
+// Commit replaces a reservation with the tokens the call actually used, as
+// reported in StandardResponse.Tokens. Commit with actual set to 0 releases
+// the reservation of a failed call.
+func (b *TokenBudget) Commit(reserved, actual int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.reserved -= reserved
+	if b.reserved < 0 {
+		b.reserved = 0
+	}
+	b.used += actual
+}
+
+// Remaining returns the tokens neither used nor reserved.
+func (b *TokenBudget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.limit - b.used - b.reserved
+}
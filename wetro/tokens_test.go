@@ -0,0 +1,113 @@
+package wetro
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	// Regression values: the estimates each family gave for these samples
+	// when the estimators were tuned. They are not counts measured with the
+	// providers' tokenizers, so a change here should be a deliberate one.
+	samples := []string{
+		"hello world",
+		"Hello, world!",
+		"The quick brown fox jumps over the lazy dog.",
+		"tiktoken is great!",
+		"antidisestablishmentarianism",
+		"2 + 2 = 4",
+		"Summarize the meeting notes from Tuesday in three bullet points.",
+		"Internationalization of 1234567 records.",
+	}
+	families := []struct {
+		model  ChatModel
+		tokens []int
+	}{
+		{GPT4O, []int{2, 4, 10, 5, 7, 7, 12, 12}},
+		{Claude35Sonnet20241022, []int{2, 4, 10, 5, 8, 7, 14, 13}},
+		{Llama3370BVersatile, []int{2, 4, 10, 5, 7, 7, 12, 12}},
+		{Mixtral8x7B32768, []int{2, 4, 10, 5, 9, 5, 17, 17}},
+	}
+
+	for _, family := range families {
+		t.Run(string(family.model.Family()), func(t *testing.T) {
+			for i, text := range samples {
+				if got := EstimateTokens(family.model, text); got != family.tokens[i] {
+					t.Errorf("EstimateTokens(%s, %q) = %d, want %d", family.model, text, got, family.tokens[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTokenEstimatorFamilies(t *testing.T) {
+	tests := map[ChatModel]ModelFamily{
+		GPT4OMini:                 ModelFamilyOpenAI,
+		O3Mini:                    ModelFamilyOpenAI,
+		ChatGPT4Latest:            ModelFamilyOpenAI,
+		Claude37Sonnet20250219:    ModelFamilyClaude,
+		Llama3370BVersatile:       ModelFamilyLlama,
+		DeepseekR1DistillLlama70b: ModelFamilyLlama,
+		Mixtral8x7B32768:          ModelFamilyOther,
+	}
+	for model, family := range tests {
+		if got := model.Family(); got != family {
+			t.Errorf("%s.Family() = %s, want %s", model, got, family)
+		}
+	}
+
+	text := strings.Repeat("Internationalization of 1234567 records. ", 20)
+	openai := EstimateTokens(GPT4O, text)
+	if claude := EstimateTokens(Claude35Sonnet20241022, text); claude < openai {
+		t.Errorf("Expected Claude to use at least as many tokens as OpenAI, got %d < %d", claude, openai)
+	}
+	if other := EstimateTokens(Mixtral8x7B32768, text); other <= openai {
+		t.Errorf("Expected digit-splitting tokenizers to use more tokens, got %d <= %d", other, openai)
+	}
+
+	if EstimateTokens(GPT4O, "日本語のテキスト") != 8 {
+		t.Error("Expected one token per CJK character")
+	}
+	if GPT4O.ContextWindow() != 128000 || Claude3Haiku20240307.ContextWindow() != 200000 || ChatModel("unknown").ContextWindow() != 0 {
+		t.Error("Unexpected context windows")
+	}
+}
+
+func TestTokenPreflight(t *testing.T) {
+	req := TextGenerationRequest{
+		Model: GPT4O,
+		Messages: []MessageObject{
+			{Role: RoleSystem, Content: "Be brief."},
+			{Role: RoleUser, Content: "Hello, world!"},
+		},
+	}
+	if got := req.EstimatedTokens(); got != 3+4+4+4 {
+		t.Errorf("Expected 15 estimated tokens, got %d", got)
+	}
+
+	history := []Message{
+		NewMessage(RoleUser, strings.Repeat("word ", 100)),
+		NewMessage(RoleAssistant, "ok"),
+		NewMessage(RoleUser, "next"),
+	}
+	trimmed := TrimHistoryToTokens(GPT4O, history, 20)
+	if len(trimmed) != 2 || trimmed[0].Content() != "ok" {
+		t.Errorf("Expected the long message to be trimmed, got %v", trimmed)
+	}
+
+	budget := NewTokenBudget(100)
+	if err := budget.Reserve(60); err != nil {
+		t.Fatalf("Reserve failed: %v", err)
+	}
+	if err := budget.Reserve(50); !errors.Is(err, ErrTokenBudgetExceeded) {
+		t.Errorf("Expected ErrTokenBudgetExceeded, got %v", err)
+	}
+	budget.Commit(60, 45)
+	if budget.Remaining() != 55 {
+		t.Errorf("Expected 55 tokens left, got %d", budget.Remaining())
+	}
+	if err := budget.Reserve(50); err != nil {
+		t.Errorf("Expected the reservation to fit after commit, got %v", err)
+	}
+}
@@ -17,7 +17,10 @@ type toolsClient struct {
 }
 
 // CategorizeData categorizes data
-func (c *toolsClient) CategorizeData(ctx context.Context, payload CategorizeRequest) (StandardResponse, error) {
+func (c *toolsClient) CategorizeData(ctx context.Context, payload CategorizeRequest, opts ...CallOption) (StandardResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
 	var response StandardResponse
 
 	err := c.client.doRequest(ctx, http.MethodPost, "/categorize/", nil, payload, &response)
@@ -28,9 +31,21 @@ func (c *toolsClient) CategorizeData(ctx context.Context, payload CategorizeRequ
 }
 
 // GenerateText generates text
-func (c *toolsClient) GenerateText(ctx context.Context, payload TextGenerationRequest) (StandardResponse, error) {
+func (c *toolsClient) GenerateText(ctx context.Context, payload TextGenerationRequest, opts ...CallOption) (StandardResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
 	var response StandardResponse
 
+	v := newValidator()
+
+	if !payload.validate(v) {
+		return StandardResponse{}, *newValidationError("Validation Error", v.errors)
+	}
+	if model := callOptionsFrom(ctx).model; model != "" {
+		payload.Model = model
+	}
+
 	err := c.client.doRequest(ctx, http.MethodPost, "/text-generation/", nil, payload, &response)
 
 	if err != nil {
@@ -40,7 +55,10 @@ func (c *toolsClient) GenerateText(ctx context.Context, payload TextGenerationRe
 }
 
 // ImageToText generates text from an image
-func (c *toolsClient) ImageToText(ctx context.Context, payload ImageToTextRequest) (StandardResponse, error) {
+func (c *toolsClient) ImageToText(ctx context.Context, payload ImageToTextRequest, opts ...CallOption) (StandardResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
 	var response StandardResponse
 
 	err := c.client.doRequest(ctx, http.MethodPost, "/image-to-text/", nil, payload, &response)
@@ -52,7 +70,10 @@ func (c *toolsClient) ImageToText(ctx context.Context, payload ImageToTextReques
 }
 
 // ExtractData extracts data from a website
-func (c *toolsClient) ExtractData(ctx context.Context, payload DataExtractionRequest) (StandardResponse, error) {
+func (c *toolsClient) ExtractData(ctx context.Context, payload DataExtractionRequest, opts ...CallOption) (StandardResponse, error) {
+	ctx, cancel := withCallOptions(ctx, opts)
+	defer cancel()
+
 	var response StandardResponse
 
 	err := c.client.doRequest(ctx, http.MethodPost, "/data-extraction/", nil, payload, &response)
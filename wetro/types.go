@@ -5,7 +5,10 @@
 
 package wetro
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // ChatModel represents the available chat models supported by the API.
 // Each model has specific capabilities and performance characteristics.
@@ -59,6 +62,10 @@ const (
 	ResourceTypeFile    ResourceType = "file"
 	ResourceTypeJSON    ResourceType = "json"
 	ResourceTypeYouTube ResourceType = "youtube"
+
+	// ResourceTypeAuto asks InsertResource to detect the type of the
+	// resource with DetectResourceType. It is never sent to the API.
+	ResourceTypeAuto ResourceType = "auto"
 )
 
 // StandardResponse represents the standard API response structure used across most endpoints.
@@ -69,6 +76,12 @@ type StandardResponse struct {
 	Response any  `json:"response,omitempty"`
 }
 
+// Text returns the text of the response, which the API sends either as a
+// plain string or as an object holding the text.
+func (r StandardResponse) Text() string {
+	return responseText(r.Response)
+}
+
 // CollectionCreateResponse contains the response from creating a collection.
 // It includes the success status and the ID of the created collection.
 type CollectionCreateResponse struct {
@@ -130,6 +143,10 @@ type ResourceInsertResponse struct {
 	ResourceID string `json:"resource_id"`
 	Success    bool   `json:"success"`
 	Tokens     int    `json:"tokens,omitempty"`
+
+	// Set when the content was already in the collection and was not
+	// inserted again, see WithDedupeStore
+	Duplicate bool `json:"-"`
 }
 
 // ResourceDeleteRequest represents a request to remove a resource from a collection.
@@ -142,6 +159,70 @@ type ResourceDeleteResponse struct {
 	Success bool `json:"success"`
 }
 
+// ResourceItem represents a resource stored in a collection.
+// It contains the information needed to audit the contents of a collection.
+type ResourceItem struct {
+
+	// The unique identifier of the resource
+	ResourceID string `json:"resource_id"`
+
+	// The type the resource was inserted as
+	Type ResourceType `json:"type"`
+
+	// The source of the resource (URL, uploaded file URL or raw content)
+	Source string `json:"resource"`
+
+	// The size of the resource in bytes
+	Size int64 `json:"size"`
+
+	// The processing status of the resource
+	Status string `json:"status"`
+
+	// The timestamp when the resource was created
+	CreatedAt string `json:"created_at"`
+}
+
+// ListResourcesRequest represents a request to list the resources in a collection.
+type ListResourcesRequest struct {
+	CollectionID string
+
+	// (optional) The page to fetch, starting from 1
+	Page int
+
+	// (optional) The number of resources per page
+	PageSize int
+}
+
+func (r *ListResourcesRequest) validate(v *validator) bool {
+	v.check(r.CollectionID != "", "collection_id", "collection_id should not be empty")
+	v.check(r.Page >= 0, "page", "page should not be negative")
+	v.check(r.PageSize >= 0, "page_size", "page_size should not be negative")
+	return v.valid()
+}
+
+// ListResourcesResponse contains the response from listing the resources in a collection.
+type ListResourcesResponse struct {
+	//Total number of resources in the collection
+	Count int `json:"count"`
+
+	//URL for the next pagination item
+	Next string `json:"next"`
+
+	//URL for the previous pagination item.
+	Previous string `json:"previous"`
+
+	//The resources on the current page.
+	Results []ResourceItem `json:"results"`
+}
+
+// GetResourceResponse contains the response from retrieving a resource.
+// It includes success status, whether the resource was found, and its details.
+type GetResourceResponse struct {
+	Success  bool         `json:"success"`
+	Found    bool         `json:"found"`
+	Resource ResourceItem `json:"resource"`
+}
+
 // QueryRequest represents a request to query a collection
 type QueryRequest struct {
 	CollectionID string `json:"collection_id"`
@@ -171,7 +252,8 @@ func (r *QueryRequest) validate(v *validator) bool {
 }
 
 // Message represents a single message in a chat conversation.
-// It's a map of string key-value pairs for flexibility.
+// It's a map of string key-value pairs for flexibility, holding the
+// "role" and "content" of the message.
 type Message map[string]string
 
 // ChatRequest represents a request to chat with a collection.
@@ -183,6 +265,16 @@ type ChatRequest struct {
 	Stream       bool      `json:"stream"`
 }
 
+func (r *ChatRequest) validate(v *validator) bool {
+	v.check(r.CollectionID != "", "collection_id", "collection_id should not be empty")
+	for i, m := range r.ChatHistory {
+		if err := m.Validate(); err != nil {
+			v.addError(fmt.Sprintf("chat_history[%d]", i), err.Error())
+		}
+	}
+	return v.valid()
+}
+
 type DeleteCollectionResponse struct {
 	Message string `json:"message"`
 	Success bool   `json:"success"`
@@ -206,7 +298,7 @@ type CategorizeRequest struct {
 
 type MessageObject struct {
 
-	// The role of the message sender (e.g., "user", "assistant")
+	// The role of the message sender (e.g., RoleUser, RoleAssistant)
 	Role string `json:"role"`
 
 	// The actual message content
@@ -219,6 +311,16 @@ type TextGenerationRequest struct {
 	Model    ChatModel       `json:"model,omitempty"`
 }
 
+func (r *TextGenerationRequest) validate(v *validator) bool {
+	v.check(len(r.Messages) > 0, "messages", "messages should not be empty")
+	for i, m := range r.Messages {
+		if err := m.Validate(); err != nil {
+			v.addError(fmt.Sprintf("messages[%d]", i), err.Error())
+		}
+	}
+	return v.valid()
+}
+
 // ImageToTextRequest represents a request to generate text from an image.
 type ImageToTextRequest struct {
 	ImageURL string `json:"image_url"`
@@ -7,8 +7,12 @@ package wetro
 import (
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -33,9 +37,65 @@ func GenerateID() (string, error) {
 	return uuid, nil
 }
 
-func parseError(resp *http.Response) string {
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place, so readers never observe a partially written file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// newAPIError builds the error of a failed response. The body is read
+// once, for both the message and the payload.
+func newAPIError(resp *http.Response) *APIError {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &APIError{
+			Message:    "Failed to read error response",
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	var errorResp struct {
+		Payload any `json:"payload,omitempty"`
+	}
+	if err := json.Unmarshal(body, &errorResp); err != nil {
+		return &APIError{
+			Message:    "Failed to parse error response",
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	return &APIError{
+		Message:    parseError(body),
+		StatusCode: resp.StatusCode,
+		Payload:    errorResp.Payload,
+	}
+}
+
+// isNotFound reports whether err is a 404 response of the API.
+func isNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+func parseError(body []byte) string {
 	var errorData map[string]any
-	if err := json.NewDecoder(resp.Body).Decode(&errorData); err != nil {
+	if err := json.Unmarshal(body, &errorData); err != nil {
 		return "Unknown error"
 	}
 